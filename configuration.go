@@ -2,43 +2,278 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/Fuwn/iku/iku"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 )
 
-type Configuration struct {
-	GroupSingleLineFunctions bool   `json:"group_single_line_functions"`
-	CommentMode             string `json:"comment_mode"`
+type loadedConfigurationLayer struct {
+	layer *iku.ConfigurationLayer
+	err   error
 }
 
-func (configuration Configuration) commentMode() (CommentMode, error) {
-	switch strings.ToLower(configuration.CommentMode) {
-	case "", "follow":
-		return CommentsFollow, nil
-	case "precede":
-		return CommentsPrecede, nil
-	case "standalone":
-		return CommentsStandalone, nil
-	default:
-		return 0, fmt.Errorf("invalid comment_mode: %q (use follow, precede, or standalone)", configuration.CommentMode)
+type configurationResolver struct {
+	overridePath            string
+	workingDirectory        string
+	mutex                   sync.Mutex
+	directoryConfigurations map[string][]string
+	repositoryDirectories   map[string]bool
+	loadedLayers            map[string]loadedConfigurationLayer
+}
+
+func newConfigurationResolver(overridePath string) *configurationResolver {
+	workingDirectory, _ := os.Getwd()
+
+	return &configurationResolver{
+		overridePath:            overridePath,
+		workingDirectory:        workingDirectory,
+		directoryConfigurations: make(map[string][]string),
+		repositoryDirectories:   make(map[string]bool),
+		loadedLayers:            make(map[string]loadedConfigurationLayer),
+	}
+}
+
+func (r *configurationResolver) resolve(filePath string) (iku.Configuration, string, error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	layers, err := r.layers(filePath)
+
+	if err != nil || len(layers) == 0 {
+		return iku.Configuration{}, "", err
+	}
+
+	nearestConfigurationPath := layers[len(layers)-1].Path
+	configuration, err := iku.MergeConfigurationLayers(layers, filePath)
+
+	if err != nil {
+		var configurationError *iku.ConfigurationError
+
+		if errors.As(err, &configurationError) && configurationError.Path == "" {
+			configurationError.Path = nearestConfigurationPath
+		}
+	}
+
+	return configuration, nearestConfigurationPath, err
+}
+
+func (r *configurationResolver) layers(filePath string) ([]*iku.ConfigurationLayer, error) {
+	var layers []*iku.ConfigurationLayer
+
+	configurationPaths := []string{r.overridePath}
+
+	if r.overridePath == "" {
+		directoryPath, err := filepath.Abs(filepath.Dir(filePath))
+
+		if err != nil {
+			return nil, err
+		}
+
+		configurationPaths = r.configurationFiles(directoryPath)
+	}
+
+	for _, configurationPath := range configurationPaths {
+		loaded, isLoaded := r.loadedLayers[configurationPath]
+
+		if !isLoaded {
+			loaded.layer, loaded.err = iku.LoadConfigurationLayer(configurationPath)
+			r.loadedLayers[configurationPath] = loaded
+		}
+
+		if loaded.err != nil {
+			return nil, loaded.err
+		}
+
+		configurationDirectory := filepath.Dir(configurationPath)
+		loaded.layer.AllowsAdapters = r.overridePath != "" || isRepositoryRoot(configurationDirectory) || configurationDirectory == r.workingDirectory
+		layers = append(layers, loaded.layer)
 	}
+
+	return layers, nil
 }
 
-func loadConfiguration() Configuration {
-	var configuration Configuration
+func (r *configurationResolver) configurationFiles(directoryPath string) []string {
+	if configurationPaths, isCached := r.directoryConfigurations[directoryPath]; isCached {
+		return configurationPaths
+	}
+
+	var configurationPaths []string
+
+	if parentDirectory := filepath.Dir(directoryPath); !r.isDiscoveryRoot(directoryPath) && parentDirectory != directoryPath {
+		configurationPaths = r.configurationFiles(parentDirectory)
+	}
+
+	if configurationPath := iku.FindConfigurationFile(directoryPath); configurationPath != "" {
+		configurationPaths = append(configurationPaths[:len(configurationPaths):len(configurationPaths)], configurationPath)
+	}
+
+	r.directoryConfigurations[directoryPath] = configurationPaths
 
-	for _, fileName := range []string{".iku.json", "iku.json"} {
-		fileData, readError := os.ReadFile(fileName)
+	return configurationPaths
+}
+
+func (r *configurationResolver) isDiscoveryRoot(directoryPath string) bool {
+	if isRepositoryRoot(directoryPath) {
+		return true
+	}
+
+	if r.isInsideRepository(directoryPath) {
+		return false
+	}
+
+	relativePath, err := filepath.Rel(r.workingDirectory, directoryPath)
+
+	return r.workingDirectory == "" || err != nil || relativePath == "." || strings.HasPrefix(relativePath, "..")
+}
+
+func (r *configurationResolver) isInsideRepository(directoryPath string) bool {
+	if isInside, isCached := r.repositoryDirectories[directoryPath]; isCached {
+		return isInside
+	}
+
+	parentDirectory := filepath.Dir(directoryPath)
+	isInside := isRepositoryRoot(directoryPath) || parentDirectory != directoryPath && r.isInsideRepository(parentDirectory)
+	r.repositoryDirectories[directoryPath] = isInside
+
+	return isInside
+}
+
+func (r *configurationResolver) findConfigurationFile(directoryPath string) string {
+	configurationPaths := r.configurationFiles(directoryPath)
+
+	if len(configurationPaths) == 0 {
+		return ""
+	}
+
+	return configurationPaths[len(configurationPaths)-1]
+}
+
+func (r *configurationResolver) resolveOptions(options iku.Options, filePath string) (iku.Options, error) {
+	if filePath == "<stdin>" {
+		workingDirectory, err := os.Getwd()
+
+		if err != nil {
+			return options, err
+		}
+
+		filePath = filepath.Join(workingDirectory, filePath)
+	}
+
+	configuration, _, err := r.resolve(filePath)
+	options.Configuration = configuration
+
+	return options, err
+}
+
+var configurationSubcommands = map[string]func(arguments []string) int{
+	"print":    runConfigurationPrintCommand,
+	"validate": runConfigurationValidateCommand,
+}
+
+func runConfigurationCommand(arguments []string) int {
+	if len(arguments) > 0 {
+		if subcommand, isSubcommand := configurationSubcommands[arguments[0]]; isSubcommand {
+			return subcommand(arguments[1:])
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "usage: iku config validate [file ...]")
+	fmt.Fprintln(os.Stderr, "       iku config print [-config path] file")
+
+	return 2
+}
+
+func runConfigurationValidateCommand(arguments []string) int {
+	configurationPaths := arguments
+
+	if len(configurationPaths) == 0 {
+		workingDirectory, err := os.Getwd()
+
+		if err != nil {
+			printError(err)
+
+			return 2
+		}
+
+		configurationPath := newConfigurationResolver("").findConfigurationFile(workingDirectory)
+
+		if configurationPath == "" {
+			fmt.Fprintln(os.Stderr, "iku: no configuration file found")
+
+			return 1
+		}
+
+		configurationPaths = []string{configurationPath}
+	}
+
+	exitCode := 0
+
+	for _, configurationPath := range configurationPaths {
+		if _, err := iku.LoadConfigurationFile(configurationPath); err != nil {
+			printError(err)
+
+			exitCode = 1
 
-		if readError != nil {
 			continue
 		}
 
-		_ = json.Unmarshal(fileData, &configuration)
+		fmt.Printf("%s: ok\n", configurationPath)
+	}
+
+	return exitCode
+}
+
+func runConfigurationPrintCommand(arguments []string) int {
+	flagSet := flag.NewFlagSet("iku config print", flag.ContinueOnError)
+	configurationPath := flagSet.String("config", "", "use the configuration file at `path` instead of discovering one")
+	flagSet.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: iku config print [-config path] file")
+		flagSet.PrintDefaults()
+	}
+
+	if err := flagSet.Parse(arguments); err != nil {
+		return 2
+	}
+
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
 
-		break
+		return 2
 	}
 
-	return configuration
+	filePath := flagSet.Arg(0)
+	sourceContent, _ := os.ReadFile(filePath)
+	layers, err := newConfigurationResolver(*configurationPath).layers(filePath)
+
+	if err != nil {
+		printError(err)
+
+		return 1
+	}
+
+	explanation, err := iku.ExplainConfiguration(layers, filePath, sourceContent)
+
+	if err != nil {
+		printError(err)
+
+		return 1
+	}
+
+	explanationContent, err := json.MarshalIndent(explanation, "", "  ")
+
+	if err != nil {
+		printError(err)
+
+		return 1
+	}
+
+	fmt.Println(string(explanationContent))
+
+	return 0
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestConfigurationResolverDiscovery(t *testing.T) {
+	rootDirectory := t.TempDir()
+	repositoryDirectory := filepath.Join(rootDirectory, "repository")
+	nestedDirectory := filepath.Join(repositoryDirectory, "nested", "deeper")
+
+	for _, directoryPath := range []string{filepath.Join(repositoryDirectory, ".git"), nestedDirectory, filepath.Join(repositoryDirectory, "plain")} {
+		if err := os.MkdirAll(directoryPath, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configurationFiles := map[string]string{
+		filepath.Join(rootDirectory, ".iku.json"):                 `{"comment_mode": "standalone"}`,
+		filepath.Join(repositoryDirectory, "iku.json"):            `{"comment_mode": "precede"}`,
+		filepath.Join(repositoryDirectory, "nested", ".iku.json"): `{"group_single_line_functions": true}`,
+	}
+
+	for configurationPath, configurationContent := range configurationFiles {
+		if err := os.WriteFile(configurationPath, []byte(configurationContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resolver := newConfigurationResolver("")
+	testCases := []struct {
+		filePath                  string
+		expectedConfigurationPath string
+	}{
+		{filepath.Join(nestedDirectory, "a.go"), filepath.Join(repositoryDirectory, "nested", ".iku.json")},
+		{filepath.Join(repositoryDirectory, "plain", "b.go"), filepath.Join(repositoryDirectory, "iku.json")},
+		{filepath.Join(rootDirectory, "c.go"), filepath.Join(rootDirectory, ".iku.json")},
+	}
+
+	for _, testCase := range testCases {
+		_, configurationPath, err := resolver.resolve(testCase.filePath)
+
+		if err != nil {
+			t.Fatalf("%s: %v", testCase.filePath, err)
+		}
+
+		if configurationPath != testCase.expectedConfigurationPath {
+			t.Errorf("%s: got %s, want %s", testCase.filePath, configurationPath, testCase.expectedConfigurationPath)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(repositoryDirectory, "iku.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, configurationPath, _ := newConfigurationResolver("").resolve(filepath.Join(repositoryDirectory, "plain", "b.go")); configurationPath != "" {
+		t.Errorf("expected discovery to stop at the repository root, got %s", configurationPath)
+	}
+
+	if _, configurationPath, _ := resolver.resolve(filepath.Join(repositoryDirectory, "plain", "b.go")); configurationPath != filepath.Join(repositoryDirectory, "iku.json") {
+		t.Errorf("expected cached discovery result, got %s", configurationPath)
+	}
+
+	overridePath := filepath.Join(rootDirectory, ".iku.json")
+	configuration, configurationPath, err := newConfigurationResolver(overridePath).resolve(filepath.Join(nestedDirectory, "a.go"))
+
+	if err != nil || configurationPath != overridePath || configuration.CommentMode != "standalone" {
+		t.Errorf("expected override %s, got %s (%v)", overridePath, configurationPath, err)
+	}
+}
+
+func TestConfigurationResolverDiscoveryWithoutRepository(t *testing.T) {
+	rootDirectory := t.TempDir()
+	projectDirectory := filepath.Join(rootDirectory, "project", "nested")
+
+	if err := os.MkdirAll(projectDirectory, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, configurationPath := range []string{filepath.Join(rootDirectory, ".iku.json"), filepath.Join(rootDirectory, "project", ".iku.json")} {
+		if err := os.WriteFile(configurationPath, []byte(`{"comment_mode": "precede"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name                      string
+		workingDirectory          string
+		filePath                  string
+		expectedConfigurationPath string
+	}{
+		{"outside the working directory", filepath.Join(rootDirectory, "elsewhere"), filepath.Join(projectDirectory, "a.go"), ""},
+		{"starting directory outside the working directory", filepath.Join(rootDirectory, "elsewhere"), filepath.Join(rootDirectory, "project", "a.go"), filepath.Join(rootDirectory, "project", ".iku.json")},
+		{"inside the working directory", filepath.Join(rootDirectory, "project"), filepath.Join(projectDirectory, "a.go"), filepath.Join(rootDirectory, "project", ".iku.json")},
+	}
+
+	for _, testCase := range testCases {
+		resolver := newConfigurationResolver("")
+		resolver.workingDirectory = testCase.workingDirectory
+		configurationPaths := resolver.configurationFiles(filepath.Dir(testCase.filePath))
+
+		if slices.Contains(configurationPaths, filepath.Join(rootDirectory, ".iku.json")) {
+			t.Errorf("%s: discovery climbed above the working directory: %v", testCase.name, configurationPaths)
+		}
+
+		if _, configurationPath, _ := resolver.resolve(testCase.filePath); configurationPath != testCase.expectedConfigurationPath {
+			t.Errorf("%s: got %q, want %q", testCase.name, configurationPath, testCase.expectedConfigurationPath)
+		}
+	}
+}
+
+func TestConfigurationResolverAdapterTrust(t *testing.T) {
+	repositoryDirectory := t.TempDir()
+	nestedDirectory := filepath.Join(repositoryDirectory, "nested")
+
+	for _, directoryPath := range []string{filepath.Join(repositoryDirectory, ".git"), nestedDirectory} {
+		if err := os.MkdirAll(directoryPath, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	adapterConfiguration := []byte(`{"adapters": {".rules": {"command": ["rules"]}}}`)
+
+	for _, configurationPath := range []string{filepath.Join(repositoryDirectory, ".iku.json"), filepath.Join(nestedDirectory, ".iku.json")} {
+		if err := os.WriteFile(configurationPath, adapterConfiguration, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := newConfigurationResolver("").resolve(filepath.Join(repositoryDirectory, "a.rules")); err != nil {
+		t.Errorf("expected adapters at the repository root to be allowed, got %v", err)
+	}
+
+	if _, _, err := newConfigurationResolver("").resolve(filepath.Join(nestedDirectory, "a.rules")); err == nil {
+		t.Error("expected adapters in a nested configuration file to be rejected")
+	}
+
+	if _, _, err := newConfigurationResolver(filepath.Join(nestedDirectory, ".iku.json")).resolve(filepath.Join(nestedDirectory, "a.rules")); err != nil {
+		t.Errorf("expected adapters in the -config file to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Fuwn/iku/engine"
+	"github.com/Fuwn/iku/internal/linediff"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func groupDiffHunks(operations []linediff.Operation, contextLineCount int) [][]linediff.Operation {
+	var hunks [][]linediff.Operation
+
+	operationIndex := 0
+
+	for operationIndex < len(operations) {
+		if operations[operationIndex].Kind == linediff.Equal {
+			operationIndex++
+
+			continue
+		}
+
+		hunkStart := max(operationIndex-contextLineCount, 0)
+		changeEnd := operationIndex
+
+		for changeEnd < len(operations) {
+			if operations[changeEnd].Kind != linediff.Equal {
+				changeEnd++
+
+				continue
+			}
+
+			equalRunEnd := changeEnd
+
+			for equalRunEnd < len(operations) && operations[equalRunEnd].Kind == linediff.Equal {
+				equalRunEnd++
+			}
+
+			if equalRunEnd == len(operations) || equalRunEnd-changeEnd > 2*contextLineCount {
+				break
+			}
+
+			changeEnd = equalRunEnd
+		}
+
+		hunkEnd := min(changeEnd+contextLineCount, len(operations))
+		hunks = append(hunks, operations[hunkStart:hunkEnd])
+		operationIndex = hunkEnd
+	}
+
+	return hunks
+}
+
+func formatHunkRange(startIndex, lineCount int) string {
+	if lineCount == 0 {
+		return fmt.Sprintf("%d,0", startIndex)
+	}
+
+	if lineCount == 1 {
+		return fmt.Sprintf("%d", startIndex+1)
+	}
+
+	return fmt.Sprintf("%d,%d", startIndex+1, lineCount)
+}
+
+func writeDiffLine(outputBuffer *bytes.Buffer, prefix byte, sourceLine string) {
+	outputBuffer.WriteByte(prefix)
+	outputBuffer.WriteString(sourceLine)
+
+	if !strings.HasSuffix(sourceLine, "\n") {
+		outputBuffer.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+func diffPath(filename string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filename), "./")
+}
+
+func unifiedDiff(filename string, originalSource, formattedSource []byte, contextLineCount int) []byte {
+	var outputBuffer bytes.Buffer
+
+	originalLines := linediff.SplitLines(originalSource)
+	formattedLines := linediff.SplitLines(formattedSource)
+	hunks := groupDiffHunks(linediff.Compute(originalLines, formattedLines), max(contextLineCount, 0))
+
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(&outputBuffer, "--- a/%s\n", diffPath(filename))
+	fmt.Fprintf(&outputBuffer, "+++ b/%s\n", diffPath(filename))
+
+	for _, hunk := range hunks {
+		originalLineCount := 0
+		formattedLineCount := 0
+
+		for _, operation := range hunk {
+			switch operation.Kind {
+			case linediff.Equal:
+				originalLineCount++
+				formattedLineCount++
+			case linediff.Delete:
+				originalLineCount++
+			case linediff.Insert:
+				formattedLineCount++
+			}
+		}
+
+		fmt.Fprintf(&outputBuffer, "@@ -%s +%s @@\n", formatHunkRange(hunk[0].OriginalIndex, originalLineCount), formatHunkRange(hunk[0].FormattedIndex, formattedLineCount))
+
+		for _, operation := range hunk {
+			switch operation.Kind {
+			case linediff.Equal:
+				writeDiffLine(&outputBuffer, ' ', originalLines[operation.OriginalIndex])
+			case linediff.Delete:
+				writeDiffLine(&outputBuffer, '-', originalLines[operation.OriginalIndex])
+			case linediff.Insert:
+				writeDiffLine(&outputBuffer, '+', formattedLines[operation.FormattedIndex])
+			}
+		}
+	}
+
+	return outputBuffer.Bytes()
+}
+
+func parseDiffLineRanges(diffContent []byte) (map[string][]engine.LineRange, error) {
+	changedLineRanges := make(map[string][]engine.LineRange)
+	currentPath := ""
+
+	for _, diffLine := range strings.Split(string(diffContent), "\n") {
+		if targetPath, isTargetHeader := strings.CutPrefix(diffLine, "+++ "); isTargetHeader {
+			targetPath, _, _ = strings.Cut(targetPath, "\t")
+			currentPath = ""
+
+			if targetPath != "/dev/null" {
+				currentPath = filepath.FromSlash(strings.TrimPrefix(targetPath, "b/"))
+			}
+
+			continue
+		}
+
+		hunkHeader, isHunkHeader := strings.CutPrefix(diffLine, "@@ ")
+
+		if !isHunkHeader || currentPath == "" {
+			continue
+		}
+
+		hunkFields := strings.Fields(hunkHeader)
+
+		if len(hunkFields) < 2 || !strings.HasPrefix(hunkFields[1], "+") {
+			return nil, fmt.Errorf("invalid hunk header: %q", diffLine)
+		}
+
+		startValue, countValue, hasCount := strings.Cut(hunkFields[1][1:], ",")
+		startLine, err := strconv.Atoi(startValue)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid hunk header: %q", diffLine)
+		}
+
+		lineCount := 1
+
+		if hasCount {
+			if lineCount, err = strconv.Atoi(countValue); err != nil {
+				return nil, fmt.Errorf("invalid hunk header: %q", diffLine)
+			}
+		}
+
+		if lineCount == 0 {
+			continue
+		}
+
+		changedLineRanges[currentPath] = append(changedLineRanges[currentPath], engine.LineRange{Start: startLine, End: startLine + lineCount - 1})
+	}
+
+	return changedLineRanges, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"github.com/Fuwn/iku/engine"
+	"github.com/Fuwn/iku/iku"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUnifiedDiffMinimalHunks(t *testing.T) {
+	originalSource := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	formattedSource := "a\n\nb\nc\nd\ne\nf\ng\nh\ni\n\nj\n"
+	expectedOutput := `--- a/test.go
++++ b/test.go
+@@ -1,2 +1,3 @@
+ a
++
+ b
+@@ -9,2 +10,3 @@
+ i
++
+ j
+`
+	diffOutput := string(unifiedDiff("./test.go", []byte(originalSource), []byte(formattedSource), 1))
+
+	if diffOutput != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", diffOutput, expectedOutput)
+	}
+}
+
+func TestUnifiedDiffMergesNearbyHunks(t *testing.T) {
+	originalSource := "a\n\n\nb\nc\nd\n"
+	formattedSource := "a\nb\nc\n\nd\n"
+	expectedOutput := `--- a/test.go
++++ b/test.go
+@@ -1,6 +1,5 @@
+ a
+-
+-
+ b
+ c
++
+ d
+`
+	diffOutput := string(unifiedDiff("test.go", []byte(originalSource), []byte(formattedSource), 3))
+
+	if diffOutput != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", diffOutput, expectedOutput)
+	}
+}
+
+func TestUnifiedDiffMissingTrailingNewline(t *testing.T) {
+	originalSource := "a\nb"
+	formattedSource := "a\nb\n"
+	expectedOutput := `--- a/test.go
++++ b/test.go
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`
+	diffOutput := string(unifiedDiff("test.go", []byte(originalSource), []byte(formattedSource), 3))
+
+	if diffOutput != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", diffOutput, expectedOutput)
+	}
+}
+
+func TestUnifiedDiffIdenticalSources(t *testing.T) {
+	if diffOutput := unifiedDiff("test.go", []byte("a\n"), []byte("a\n"), 3); diffOutput != nil {
+		t.Errorf("expected no diff for identical sources, got:\n%s", diffOutput)
+	}
+}
+
+func TestParseDiffLineRanges(t *testing.T) {
+	diffContent := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ func main() {
++	x := 1
++	y := 2
+@@ -10 +12 @@ func main() {
+-	z := 3
++	z := 4
+@@ -20,2 +21,0 @@ func main() {
+-	a := 1
+-	b := 2
+diff --git a/old.ts b/old.ts
+--- a/old.ts
++++ /dev/null
+@@ -1,1 +0,0 @@
+-const x = 1;
+--- web/app.ts	2024-01-01 00:00:00
++++ web/app.ts	2024-01-02 00:00:00
+@@ -1 +1,3 @@
+`
+	changedLineRanges, err := parseDiffLineRanges([]byte(diffContent))
+
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	expectedLineRanges := map[string][]engine.LineRange{
+		"main.go":                        {{Start: 4, End: 5}, {Start: 12, End: 12}},
+		filepath.FromSlash("web/app.ts"): {{Start: 1, End: 3}},
+	}
+
+	if !reflect.DeepEqual(changedLineRanges, expectedLineRanges) {
+		t.Errorf("got %v, want %v", changedLineRanges, expectedLineRanges)
+	}
+}
+
+func TestFormatChangedLinesLeavesUnchangedLines(t *testing.T) {
+	diffContent := `--- a/legacy.go
++++ b/legacy.go
+@@ -4,0 +5 @@ func main() {
++    if a>b {
+`
+	changedLineRanges, err := parseDiffLineRanges([]byte(diffContent))
+
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+
+	legacySource := "package main\nfunc main() {\n    a:=1\n    b:=2\n    if a>b {\n    }\n    c  :=  3\n}\n"
+	formattedResult, err := iku.Format([]byte(legacySource), "legacy.go", iku.Options{LineRanges: changedLineRanges["legacy.go"]})
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if expectedOutput := "package main\nfunc main() {\n    a:=1\n    b:=2\n\n\tif a > b {\n    }\n    c  :=  3\n}\n"; string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%q\nwant:\n%q", formattedResult, expectedOutput)
+	}
+}
@@ -0,0 +1,56 @@
+package engine
+
+import "fmt"
+
+const (
+	RuleScopeBoundary      = "scope-boundary"
+	RuleTypeTransition     = "type-transition"
+	RuleTopLevelTransition = "top-level-transition"
+	RuleExtraBlank         = "extra-blank"
+)
+
+type Diagnostic struct {
+	Line              int
+	Rule              string
+	PreviousStatement string
+	NextStatement     string
+	BlankLines        int
+}
+
+func (d Diagnostic) Message() string {
+	missingBlankLines := "missing blank line"
+
+	if d.BlankLines > 1 {
+		missingBlankLines = fmt.Sprintf("expected %d blank lines", d.BlankLines)
+	}
+
+	switch d.Rule {
+	case RuleScopeBoundary:
+		return fmt.Sprintf("%s between %s and %s at a scope boundary", missingBlankLines, d.PreviousStatement, d.NextStatement)
+	case RuleTypeTransition:
+		return fmt.Sprintf("%s between %s and %s, which are different statement types", missingBlankLines, d.PreviousStatement, d.NextStatement)
+	case RuleTopLevelTransition:
+		return fmt.Sprintf("%s between top-level %s and %s", missingBlankLines, d.PreviousStatement, d.NextStatement)
+	default:
+		return fmt.Sprintf("extra blank line between %s and %s", d.PreviousStatement, d.NextStatement)
+	}
+}
+
+func statementLabel(statementType string, event LineEvent) string {
+	switch {
+	case event.IsPackageDecl:
+		return "package"
+	case statementType != "" && !event.IsClosingBrace:
+		return statementType
+	case event.IsCommentOnly:
+		return "comment"
+	case event.IsClosingBrace && statementType != "":
+		return "end of " + statementType
+	case event.IsClosingBrace:
+		return "closing brace"
+	case event.IsCaseLabel:
+		return "case label"
+	default:
+		return "statement"
+	}
+}
@@ -0,0 +1,14 @@
+package engine
+
+type EditKind int
+
+const (
+	InsertBlank EditKind = iota
+	DeleteBlank
+)
+
+type Edit struct {
+	Kind EditKind
+	Line int
+	Rule string
+}
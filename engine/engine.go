@@ -10,62 +10,124 @@ const (
 	CommentsStandalone
 )
 
+type LineRange struct {
+	Start int
+	End   int
+}
+
 type Engine struct {
-	CommentMode           CommentMode
-	GroupSingleLineScopes bool
+	CommentMode              CommentMode
+	GroupSingleLineScopes    bool
+	LineRanges               []LineRange
+	StatementGroups          map[string]string
+	ScopedKinds              map[string]bool
+	PreserveBlankLines       bool
+	TopLevelBlankLines       int
+	ScopeBoundaryBlankLines  int
+	TypeTransitionBlankLines int
 }
 
-func (e *Engine) format(events []LineEvent, resultBuilder *strings.Builder) {
+func (e *Engine) statementGroup(statementType string) string {
+	if groupName, isGrouped := e.StatementGroups[statementType]; isGrouped {
+		return groupName
+	}
+
+	return statementType
+}
+
+func (e *Engine) blankLineCount(blankLineRule string, isTopLevelGap bool) int {
+	blankLineCount := e.TypeTransitionBlankLines
+
+	switch {
+	case blankLineRule == "":
+		return 0
+	case isTopLevelGap:
+		blankLineCount = e.TopLevelBlankLines
+	case blankLineRule == RuleScopeBoundary:
+		blankLineCount = e.ScopeBoundaryBlankLines
+	}
+
+	return max(blankLineCount, 1)
+}
+
+func (e *Engine) isScoped(event LineEvent) bool {
+	if e.ScopedKinds == nil || event.ScopeKind == "" {
+		return event.IsScoped
+	}
+
+	return e.ScopedKinds[event.ScopeKind]
+}
+
+type decisions struct {
+	edits           []Edit
+	diagnostics     []Diagnostic
+	blankLineRules  []string
+	extraBlankLines []int
+}
+
+func (e *Engine) decide(events []LineEvent) decisions {
 	hasWrittenContent := false
 	previousWasOpenBrace := false
 	previousStatementType := ""
+	previousStatementLabel := "start of file"
 	previousWasComment := false
+	previousWasPackageDecl := false
 	previousWasTopLevel := false
 	previousWasScoped := false
 	previousWasSingleLineScope := false
 
-	for eventIndex, event := range events {
-		if event.InRawString {
-			if hasWrittenContent {
-				resultBuilder.WriteByte('\n')
-			}
+	var pendingBlankIndices []int
+	var edits []Edit
+	var diagnostics []Diagnostic
 
-			resultBuilder.WriteString(event.Content)
+	blankLineRules := make([]string, len(events))
+	extraBlankLines := make([]int, len(events))
 
+	for eventIndex, event := range events {
+		if event.InRawString {
 			hasWrittenContent = true
 
 			continue
 		}
 
 		if event.IsBlank {
+			if event.IsVerbatim {
+				hasWrittenContent = true
+			} else {
+				pendingBlankIndices = append(pendingBlankIndices, eventIndex)
+			}
+
 			continue
 		}
 
-		currentStatementType := event.StatementType
+		currentStatementType := e.statementGroup(event.StatementType)
 
 		if event.IsPackageDecl {
 			currentStatementType = "package"
 		}
 
-		needsBlankLine := false
+		blankLineRule := ""
+		blankLineStatementLabel := statementLabel(event.StatementType, event)
 		currentIsTopLevel := event.HasASTInfo && event.IsTopLevel
-		currentIsScoped := event.HasASTInfo && event.IsScoped
+		currentIsScoped := event.HasASTInfo && e.isScoped(event)
 		currentIsSingleLineScope := currentIsScoped && !event.IsOpeningBrace && !event.IsClosingBrace
+		blankLineIsTopLevel := currentIsTopLevel && previousWasTopLevel
+		isStatementGap := hasWrittenContent && !event.IsVerbatim && !previousWasOpenBrace && !event.IsClosingBrace && !event.IsCaseLabel && !event.IsContinuation
 
-		if hasWrittenContent && !previousWasOpenBrace && !event.IsClosingBrace && !event.IsCaseLabel && !event.IsContinuation {
+		if isStatementGap {
 			if currentIsTopLevel && previousWasTopLevel && currentStatementType != previousStatementType {
 				if e.CommentMode != CommentsFollow || !previousWasComment {
-					needsBlankLine = true
+					blankLineRule = RuleTopLevelTransition
 				}
 			} else if event.HasASTInfo && (currentIsScoped || previousWasScoped) {
 				if e.GroupSingleLineScopes && currentIsSingleLineScope && previousWasSingleLineScope && currentStatementType == previousStatementType {
-					needsBlankLine = false
+					blankLineRule = ""
 				} else if e.CommentMode != CommentsFollow || !previousWasComment {
-					needsBlankLine = true
+					blankLineRule = RuleScopeBoundary
 				}
 			} else if currentStatementType != "" && previousStatementType != "" && currentStatementType != previousStatementType {
 				if e.CommentMode != CommentsFollow || !previousWasComment {
-					needsBlankLine = true
+					blankLineRule = RuleTypeTransition
 				}
 			}
 
@@ -77,38 +139,79 @@ func (e *Engine) format(events []LineEvent, resultBuilder *strings.Builder) {
 
 					if nextNonCommentEvent.HasASTInfo {
 						nextIsTopLevel := nextNonCommentEvent.IsTopLevel
-						nextIsScoped := nextNonCommentEvent.IsScoped
+						nextIsScoped := e.isScoped(nextNonCommentEvent)
+						nextStatementType := e.statementGroup(nextNonCommentEvent.StatementType)
 
-						if nextIsTopLevel && previousWasTopLevel && nextNonCommentEvent.StatementType != previousStatementType {
-							needsBlankLine = true
+						if nextIsTopLevel && previousWasTopLevel && nextStatementType != previousStatementType {
+							blankLineRule = RuleTopLevelTransition
 						} else if nextIsScoped || previousWasScoped {
-							needsBlankLine = true
-						} else if nextNonCommentEvent.StatementType != "" && previousStatementType != "" && nextNonCommentEvent.StatementType != previousStatementType {
-							needsBlankLine = true
+							blankLineRule = RuleScopeBoundary
+						} else if nextStatementType != "" && previousStatementType != "" && nextStatementType != previousStatementType {
+							blankLineRule = RuleTypeTransition
+						}
+
+						if blankLineRule != "" {
+							blankLineStatementLabel = statementLabel(nextNonCommentEvent.StatementType, nextNonCommentEvent)
+							blankLineIsTopLevel = nextIsTopLevel && previousWasTopLevel
 						}
 					}
 				}
 			}
 		}
 
-		if needsBlankLine {
-			resultBuilder.WriteByte('\n')
-		}
+		if event.IsVerbatim || !e.isGapInRange(pendingBlankIndices, eventIndex) {
+			hasWrittenContent = hasWrittenContent || len(pendingBlankIndices) > 0
+		} else {
+			preservesBlankLine := blankLineRule == "" && e.PreserveBlankLines && isStatementGap && (e.CommentMode != CommentsFollow || !previousWasComment)
+			blankLineCount := e.blankLineCount(blankLineRule, blankLineIsTopLevel)
 
-		if hasWrittenContent {
-			resultBuilder.WriteByte('\n')
-		}
+			if preservesBlankLine {
+				blankLineCount = min(len(pendingBlankIndices), 1)
+			} else if previousWasPackageDecl {
+				blankLineCount = min(blankLineCount, 1)
+			}
 
-		resultBuilder.WriteString(event.Content)
+			keptBlankCount := min(len(pendingBlankIndices), blankLineCount)
+			extraBlankIndices := pendingBlankIndices[keptBlankCount:]
+			insertedBlankCount := blankLineCount - keptBlankCount
+			blankLineRules[eventIndex] = blankLineRule
+			extraBlankLines[eventIndex] = len(extraBlankIndices)
+
+			if len(pendingBlankIndices) < blankLineCount {
+				diagnostics = append(diagnostics, Diagnostic{Line: eventIndex, Rule: blankLineRule, PreviousStatement: previousStatementLabel, NextStatement: blankLineStatementLabel, BlankLines: blankLineCount})
+			}
 
+			for pendingIndex, blankIndex := range pendingBlankIndices {
+				switch {
+				case pendingIndex >= keptBlankCount:
+					edits = append(edits, Edit{Kind: DeleteBlank, Line: blankIndex, Rule: RuleExtraBlank})
+				case !preservesBlankLine && events[blankIndex].Content != "":
+					edits = append(edits, Edit{Kind: DeleteBlank, Line: blankIndex, Rule: RuleExtraBlank})
+
+					insertedBlankCount++
+				}
+			}
+
+			for range insertedBlankCount {
+				edits = append(edits, Edit{Kind: InsertBlank, Line: eventIndex, Rule: blankLineRule})
+			}
+
+			for _, blankIndex := range extraBlankIndices {
+				diagnostics = append(diagnostics, Diagnostic{Line: blankIndex, Rule: RuleExtraBlank, PreviousStatement: previousStatementLabel, NextStatement: statementLabel(event.StatementType, event)})
+			}
+		}
+
+		pendingBlankIndices = pendingBlankIndices[:0]
 		hasWrittenContent = true
 		previousWasOpenBrace = event.IsOpeningBrace || event.IsCaseLabel
 		previousWasComment = event.IsCommentOnly
+		previousWasPackageDecl = event.IsPackageDecl
+		previousStatementLabel = statementLabel(event.StatementType, event)
 
 		if event.HasASTInfo {
-			previousStatementType = event.StatementType
+			previousStatementType = currentStatementType
 			previousWasTopLevel = event.IsTopLevel
-			previousWasScoped = event.IsScoped
+			previousWasScoped = currentIsScoped
 			previousWasSingleLineScope = currentIsSingleLineScope
 		} else if currentStatementType != "" {
 			previousStatementType = currentStatementType
@@ -118,14 +221,67 @@ func (e *Engine) format(events []LineEvent, resultBuilder *strings.Builder) {
 		}
 	}
 
+	for _, blankIndex := range pendingBlankIndices {
+		edits = append(edits, Edit{Kind: DeleteBlank, Line: blankIndex, Rule: RuleExtraBlank})
+
+		if blankIndex == len(events)-1 && events[blankIndex].Content == "" {
+			continue
+		}
+
+		if len(e.LineRanges) == 0 || e.isLineInRange(blankIndex+1) {
+			diagnostics = append(diagnostics, Diagnostic{Line: blankIndex, Rule: RuleExtraBlank, PreviousStatement: previousStatementLabel, NextStatement: "end of file"})
+		}
+	}
+
+	return decisions{edits: edits, diagnostics: diagnostics, blankLineRules: blankLineRules, extraBlankLines: extraBlankLines}
+}
+
+func (e *Engine) apply(events []LineEvent, edits []Edit, resultBuilder *strings.Builder) {
+	hasWrittenContent := false
+	editIndex := 0
+
+	for eventIndex, event := range events {
+		isDeleted := false
+
+		for ; editIndex < len(edits) && edits[editIndex].Line == eventIndex; editIndex++ {
+			if edits[editIndex].Kind == DeleteBlank {
+				isDeleted = true
+
+				continue
+			}
+
+			if hasWrittenContent {
+				resultBuilder.WriteByte('\n')
+			}
+
+			hasWrittenContent = true
+		}
+
+		if isDeleted {
+			continue
+		}
+
+		if hasWrittenContent {
+			resultBuilder.WriteByte('\n')
+		}
+
+		resultBuilder.WriteString(event.Content)
+
+		hasWrittenContent = true
+	}
+
 	resultBuilder.WriteByte('\n')
 }
 
+func (e *Engine) Edits(events []LineEvent) []Edit {
+	return e.decide(events).edits
+}
+
 func (e *Engine) FormatToString(events []LineEvent) string {
 	var resultBuilder strings.Builder
 
 	resultBuilder.Grow(len(events) * 40)
-	e.format(events, &resultBuilder)
+	e.apply(events, e.Edits(events), &resultBuilder)
 
 	return resultBuilder.String()
 }
@@ -134,11 +290,39 @@ func (e *Engine) FormatToBytes(events []LineEvent) []byte {
 	var resultBuilder strings.Builder
 
 	resultBuilder.Grow(len(events) * 40)
-	e.format(events, &resultBuilder)
+	e.apply(events, e.Edits(events), &resultBuilder)
 
 	return []byte(resultBuilder.String())
 }
 
+func (e *Engine) Lint(events []LineEvent) []Diagnostic {
+	return e.decide(events).diagnostics
+}
+
+func (e *Engine) isLineInRange(lineNumber int) bool {
+	for _, lineRange := range e.LineRanges {
+		if lineNumber >= lineRange.Start && lineNumber <= lineRange.End {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Engine) isGapInRange(blankIndices []int, eventIndex int) bool {
+	if len(e.LineRanges) == 0 || e.isLineInRange(eventIndex+1) {
+		return true
+	}
+
+	for _, blankIndex := range blankIndices {
+		if e.isLineInRange(blankIndex + 1) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (e *Engine) findNextNonComment(events []LineEvent, startIndex int) int {
 	for eventIndex := startIndex; eventIndex < len(events); eventIndex++ {
 		if events[eventIndex].IsBlank {
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"slices"
 	"strings"
 	"testing"
 )
@@ -119,6 +120,237 @@ func TestEngineRawStringPassthrough(t *testing.T) {
 	}
 }
 
+func TestEngineVerbatimPassthrough(t *testing.T) {
+	events := []LineEvent{
+		{Content: "\tx := 1", TrimmedContent: "x := 1", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\t// iku:off", TrimmedContent: "// iku:off", IsCommentOnly: true},
+		{Content: "\ty := 2", TrimmedContent: "y := 2", HasASTInfo: true, StatementType: "*ast.AssignStmt", IsVerbatim: true},
+		{Content: "", TrimmedContent: "", IsBlank: true, IsVerbatim: true},
+		{Content: "\tz := 3", TrimmedContent: "z := 3", HasASTInfo: true, StatementType: "*ast.AssignStmt", IsVerbatim: true},
+		{Content: "\tif z > 0 {", TrimmedContent: "if z > 0 {", HasASTInfo: true, StatementType: "*ast.IfStmt", IsScoped: true, IsStartLine: true, IsOpeningBrace: true, IsVerbatim: true},
+		{Content: "\t}", TrimmedContent: "}", IsClosingBrace: true, HasASTInfo: true, StatementType: "*ast.IfStmt", IsScoped: true, IsVerbatim: true},
+		{Content: "\t// iku:on", TrimmedContent: "// iku:on", IsCommentOnly: true, IsVerbatim: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\tw := 4", TrimmedContent: "w := 4", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow}
+	result := formatResult(formattingEngine, events)
+	expected := "\tx := 1\n\t// iku:off\n\ty := 2\n\n\tz := 3\n\tif z > 0 {\n\t}\n\t// iku:on\n\tw := 4"
+
+	if result != expected {
+		t.Errorf("verbatim lines should pass through unchanged, got:\n%s\nwant:\n%s", result, expected)
+	}
+}
+
+func TestEngineLineRanges(t *testing.T) {
+	events := []LineEvent{
+		{Content: "\tx := 1", TrimmedContent: "x := 1", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\ty := 2", TrimmedContent: "y := 2", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\tvar a = 3", TrimmedContent: "var a = 3", HasASTInfo: true, StatementType: "var"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\tz := 3", TrimmedContent: "z := 3", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\tvar b = 4", TrimmedContent: "var b = 4", HasASTInfo: true, StatementType: "var"},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow, LineRanges: []LineRange{{Start: 5, End: 6}}}
+	result := formatResult(formattingEngine, events)
+	expected := "\tx := 1\n\n\n\ty := 2\n\n\tvar a = 3\n\n\tz := 3\n\tvar b = 4"
+
+	if result != expected {
+		t.Errorf("only gaps inside the line ranges should change, got:\n%s\nwant:\n%s", result, expected)
+	}
+}
+
+func TestEngineStatementGroups(t *testing.T) {
+	events := []LineEvent{
+		{Content: "\tx := 1", TrimmedContent: "x := 1", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\tvar y int", TrimmedContent: "var y int", HasASTInfo: true, StatementType: "var"},
+		{Content: "\t// trailing call", TrimmedContent: "// trailing call", IsCommentOnly: true},
+		{Content: "\tfoo()", TrimmedContent: "foo()", HasASTInfo: true, StatementType: "*ast.ExprStmt"},
+		{Content: "\t// binding", TrimmedContent: "// binding", IsCommentOnly: true},
+		{Content: "\tz := 2", TrimmedContent: "z := 2", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow, StatementGroups: map[string]string{"*ast.AssignStmt": "bindings", "var": "bindings"}}
+	result := formatResult(formattingEngine, events)
+	expected := "\tx := 1\n\tvar y int\n\n\t// trailing call\n\tfoo()\n\n\t// binding\n\tz := 2"
+
+	if result != expected {
+		t.Errorf("grouped statement types should not be separated, got:\n%s\nwant:\n%s", result, expected)
+	}
+}
+
+func TestEngineScopedKinds(t *testing.T) {
+	events := []LineEvent{
+		{Content: "\tselect {", TrimmedContent: "select {", HasASTInfo: true, StatementType: "*ast.SelectStmt", IsScoped: true, ScopeKind: "select", IsStartLine: true, IsOpeningBrace: true},
+		{Content: "\t}", TrimmedContent: "}", IsClosingBrace: true, HasASTInfo: true, StatementType: "*ast.SelectStmt", IsScoped: true, ScopeKind: "select"},
+		{Content: "\tselect {", TrimmedContent: "select {", HasASTInfo: true, StatementType: "*ast.SelectStmt", IsScoped: true, ScopeKind: "select", IsStartLine: true, IsOpeningBrace: true},
+		{Content: "\t}", TrimmedContent: "}", IsClosingBrace: true, HasASTInfo: true, StatementType: "*ast.SelectStmt", IsScoped: true, ScopeKind: "select"},
+		{Content: "\tgo func() {", TrimmedContent: "go func() {", HasASTInfo: true, StatementType: "*ast.GoStmt", ScopeKind: "go_func", IsStartLine: true, IsOpeningBrace: true},
+		{Content: "\t}()", TrimmedContent: "}()", IsClosingBrace: true, HasASTInfo: true, StatementType: "*ast.GoStmt", ScopeKind: "go_func"},
+		{Content: "\tgo work()", TrimmedContent: "go work()", HasASTInfo: true, StatementType: "*ast.GoStmt", IsStartLine: true},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow, ScopedKinds: map[string]bool{"go_func": true}}
+	result := formatResult(formattingEngine, events)
+	expected := "\tselect {\n\t}\n\tselect {\n\t}\n\n\tgo func() {\n\t}()\n\n\tgo work()"
+
+	if result != expected {
+		t.Errorf("configured scope kinds should decide scoping, got:\n%s\nwant:\n%s", result, expected)
+	}
+}
+
+func TestEngineLint(t *testing.T) {
+	events := []LineEvent{
+		{Content: "\tx := 1", TrimmedContent: "x := 1", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\ty := 2", TrimmedContent: "y := 2", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\tif y > 0 {", TrimmedContent: "if y > 0 {", HasASTInfo: true, StatementType: "*ast.IfStmt", IsScoped: true, IsStartLine: true, IsOpeningBrace: true},
+		{Content: "\t}", TrimmedContent: "}", IsClosingBrace: true, HasASTInfo: true, StatementType: "*ast.IfStmt", IsScoped: true},
+		{Content: "\tfoo()", TrimmedContent: "foo()", HasASTInfo: true, StatementType: "*ast.ExprStmt"},
+		{Content: "\treturn", TrimmedContent: "return", HasASTInfo: true, StatementType: "*ast.ReturnStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow}
+	expected := []Diagnostic{
+		{Line: 1, Rule: RuleExtraBlank, PreviousStatement: "*ast.AssignStmt", NextStatement: "*ast.AssignStmt"},
+		{Line: 3, Rule: RuleScopeBoundary, PreviousStatement: "*ast.AssignStmt", NextStatement: "*ast.IfStmt", BlankLines: 1},
+		{Line: 5, Rule: RuleScopeBoundary, PreviousStatement: "end of *ast.IfStmt", NextStatement: "*ast.ExprStmt", BlankLines: 1},
+		{Line: 6, Rule: RuleTypeTransition, PreviousStatement: "*ast.ExprStmt", NextStatement: "*ast.ReturnStmt", BlankLines: 1},
+		{Line: 7, Rule: RuleExtraBlank, PreviousStatement: "*ast.ReturnStmt", NextStatement: "end of file"},
+	}
+
+	if diagnostics := formattingEngine.Lint(events); !slices.Equal(diagnostics, expected) {
+		t.Errorf("got %+v, want %+v", diagnostics, expected)
+	}
+}
+
+func TestEngineEdits(t *testing.T) {
+	events := []LineEvent{
+		{Content: "\tx := 1", TrimmedContent: "x := 1", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\ty := 2", TrimmedContent: "y := 2", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\tfoo()", TrimmedContent: "foo()", HasASTInfo: true, StatementType: "*ast.ExprStmt"},
+		{Content: "  ", TrimmedContent: "", IsBlank: true},
+		{Content: "\treturn", TrimmedContent: "return", HasASTInfo: true, StatementType: "*ast.ReturnStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow}
+	expected := []Edit{
+		{Kind: DeleteBlank, Line: 1, Rule: RuleExtraBlank},
+		{Kind: InsertBlank, Line: 3, Rule: RuleTypeTransition},
+		{Kind: DeleteBlank, Line: 4, Rule: RuleExtraBlank},
+		{Kind: InsertBlank, Line: 5, Rule: RuleTypeTransition},
+		{Kind: DeleteBlank, Line: 6, Rule: RuleExtraBlank},
+	}
+
+	if edits := formattingEngine.Edits(events); !slices.Equal(edits, expected) {
+		t.Errorf("got %+v, want %+v", edits, expected)
+	}
+
+	if result, expectedResult := formattingEngine.FormatToString(events), "\tx := 1\n\ty := 2\n\n\tfoo()\n\n\treturn\n"; result != expectedResult {
+		t.Errorf("applying edits: got %q, want %q", result, expectedResult)
+	}
+}
+
+func TestEngineExplain(t *testing.T) {
+	events := []LineEvent{
+		{Content: "\tx := 1", TrimmedContent: "x := 1", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\ty := 2", TrimmedContent: "y := 2", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\tif y > 0 {", TrimmedContent: "if y > 0 {", HasASTInfo: true, StatementType: "*ast.IfStmt", IsScoped: true, IsStartLine: true, IsOpeningBrace: true},
+		{Content: "\t}", TrimmedContent: "}", IsClosingBrace: true, HasASTInfo: true, StatementType: "*ast.IfStmt", IsScoped: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\treturn", TrimmedContent: "return", HasASTInfo: true, StatementType: "*ast.ReturnStmt"},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow}
+	expected := []LineDecision{
+		{Line: 0},
+		{Line: 3, RemovedBlankLines: 2},
+		{Line: 4, Rule: RuleScopeBoundary, IsScoped: true, InsertedBlankLines: 1},
+		{Line: 5, IsScoped: true},
+		{Line: 6},
+		{Line: 7, Rule: RuleScopeBoundary},
+	}
+
+	if lineDecisions := formattingEngine.Explain(events); !slices.Equal(lineDecisions, expected) {
+		t.Errorf("got %+v, want %+v", lineDecisions, expected)
+	}
+}
+
+func TestEnginePreserveBlankLines(t *testing.T) {
+	events := []LineEvent{
+		{Content: "\tx := 1", TrimmedContent: "x := 1", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\ty := 2", TrimmedContent: "y := 2", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\tz := 3", TrimmedContent: "z := 3", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\t// call", TrimmedContent: "// call", IsCommentOnly: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\tfoo()", TrimmedContent: "foo()", HasASTInfo: true, StatementType: "*ast.ExprStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "}", TrimmedContent: "}", IsClosingBrace: true},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow, PreserveBlankLines: true}
+	result := formatResult(formattingEngine, events)
+	expected := "\tx := 1\n\n\ty := 2\n\tz := 3\n\n\t// call\n\tfoo()\n}"
+
+	if result != expected {
+		t.Errorf("single blank lines between statements should be preserved, got:\n%s\nwant:\n%s", result, expected)
+	}
+}
+
+func TestEngineBlankLineCounts(t *testing.T) {
+	events := []LineEvent{
+		{Content: "func a() {", TrimmedContent: "func a() {", HasASTInfo: true, StatementType: "func", IsTopLevel: true, IsScoped: true, IsStartLine: true, IsOpeningBrace: true},
+		{Content: "\tx := 1", TrimmedContent: "x := 1", HasASTInfo: true, StatementType: "*ast.AssignStmt"},
+		{Content: "\tif x > 0 {", TrimmedContent: "if x > 0 {", HasASTInfo: true, StatementType: "*ast.IfStmt", IsScoped: true, IsStartLine: true, IsOpeningBrace: true},
+		{Content: "\t}", TrimmedContent: "}", IsClosingBrace: true, HasASTInfo: true, StatementType: "*ast.IfStmt", IsScoped: true},
+		{Content: "\tfoo()", TrimmedContent: "foo()", HasASTInfo: true, StatementType: "*ast.ExprStmt"},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "\treturn", TrimmedContent: "return", HasASTInfo: true, StatementType: "*ast.ReturnStmt"},
+		{Content: "}", TrimmedContent: "}", IsClosingBrace: true, HasASTInfo: true, StatementType: "func", IsTopLevel: true, IsScoped: true},
+		{Content: "", TrimmedContent: "", IsBlank: true},
+		{Content: "func b() {}", TrimmedContent: "func b() {}", HasASTInfo: true, StatementType: "func", IsTopLevel: true, IsScoped: true, IsStartLine: true},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow, TopLevelBlankLines: 2, ScopeBoundaryBlankLines: 1, TypeTransitionBlankLines: 2}
+	result := formatResult(formattingEngine, events)
+	expected := "func a() {\n\tx := 1\n\n\tif x > 0 {\n\t}\n\n\tfoo()\n\n\n\treturn\n}\n\n\nfunc b() {}"
+
+	if result != expected {
+		t.Errorf("blank line counts should follow their rule, got:\n%s\nwant:\n%s", result, expected)
+	}
+
+	expectedDiagnostics := []Diagnostic{
+		{Line: 2, Rule: RuleScopeBoundary, PreviousStatement: "*ast.AssignStmt", NextStatement: "*ast.IfStmt", BlankLines: 1},
+		{Line: 4, Rule: RuleScopeBoundary, PreviousStatement: "end of *ast.IfStmt", NextStatement: "*ast.ExprStmt", BlankLines: 1},
+		{Line: 7, Rule: RuleExtraBlank, PreviousStatement: "*ast.ExprStmt", NextStatement: "*ast.ReturnStmt"},
+		{Line: 11, Rule: RuleScopeBoundary, PreviousStatement: "end of func", NextStatement: "func", BlankLines: 2},
+	}
+
+	if diagnostics := formattingEngine.Lint(events); !slices.Equal(diagnostics, expectedDiagnostics) {
+		t.Errorf("got %+v, want %+v", diagnostics, expectedDiagnostics)
+	}
+}
+
+func TestEngineBlankLineCountsAfterPackage(t *testing.T) {
+	events := []LineEvent{
+		{Content: "package main", TrimmedContent: "package main", IsPackageDecl: true},
+		{Content: "// Main runs.", TrimmedContent: "// Main runs.", IsCommentOnly: true},
+		{Content: "func main() {}", TrimmedContent: "func main() {}", HasASTInfo: true, StatementType: "func", IsTopLevel: true, IsScoped: true, IsStartLine: true},
+	}
+	formattingEngine := &Engine{CommentMode: CommentsFollow, TopLevelBlankLines: 2, ScopeBoundaryBlankLines: 2, TypeTransitionBlankLines: 2}
+	result := formatResult(formattingEngine, events)
+	expected := "package main\n\n// Main runs.\nfunc main() {}"
+
+	if result != expected {
+		t.Errorf("the package clause should be followed by one blank line, got:\n%s\nwant:\n%s", result, expected)
+	}
+}
+
 func TestEngineTopLevelDifferentTypes(t *testing.T) {
 	events := []LineEvent{
 		{Content: "type Foo struct {", TrimmedContent: "type Foo struct {", HasASTInfo: true, StatementType: "type", IsTopLevel: true, IsScoped: true, IsStartLine: true, IsOpeningBrace: true},
@@ -8,6 +8,7 @@ type LineEvent struct {
 	StatementType  string
 	IsTopLevel     bool
 	IsScoped       bool
+	ScopeKind      string
 	IsStartLine    bool
 	HasASTInfo     bool
 	IsClosingBrace bool
@@ -18,6 +19,7 @@ type LineEvent struct {
 	IsBlank        bool
 	InRawString    bool
 	IsPackageDecl  bool
+	IsVerbatim     bool
 }
 
 func NewLineEvent(content string) LineEvent {
@@ -0,0 +1,35 @@
+package engine
+
+type LineDecision struct {
+	Line               int
+	Rule               string
+	IsScoped           bool
+	InsertedBlankLines int
+	RemovedBlankLines  int
+}
+
+func (e *Engine) Explain(events []LineEvent) []LineDecision {
+	var lineDecisions []LineDecision
+
+	decided := e.decide(events)
+	editIndex := 0
+
+	for eventIndex, event := range events {
+		isDeleted := false
+		lineDecision := LineDecision{Line: eventIndex, Rule: decided.blankLineRules[eventIndex], IsScoped: event.HasASTInfo && e.isScoped(event), RemovedBlankLines: decided.extraBlankLines[eventIndex]}
+
+		for ; editIndex < len(decided.edits) && decided.edits[editIndex].Line == eventIndex; editIndex++ {
+			if decided.edits[editIndex].Kind == DeleteBlank {
+				isDeleted = true
+			} else {
+				lineDecision.InsertedBlankLines++
+			}
+		}
+
+		if !isDeleted {
+			lineDecisions = append(lineDecisions, lineDecision)
+		}
+	}
+
+	return lineDecisions
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Fuwn/iku/iku"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type fileExplanation struct {
+	Filename string                `json:"filename"`
+	Lines    []iku.LineExplanation `json:"lines"`
+}
+
+func explainFile(options iku.Options, filename string, sourceContent []byte, outputWriter io.Writer) (fileStatus, error) {
+	lineExplanations, err := iku.Explain(sourceContent, filename, options)
+
+	if err != nil {
+		return fileUnchanged, err
+	}
+
+	if *jsonFlag {
+		explanationContent, err := json.Marshal(fileExplanation{Filename: filename, Lines: lineExplanations})
+
+		if err != nil {
+			return fileUnchanged, err
+		}
+
+		fmt.Fprintln(outputWriter, string(explanationContent))
+
+		return fileUnchanged, nil
+	}
+
+	annotations := make([]string, len(lineExplanations))
+	annotationWidth := 0
+	lineNumberWidth := len(strconv.Itoa(len(lineExplanations)))
+
+	for lineIndex, lineExplanation := range lineExplanations {
+		annotations[lineIndex] = explanationAnnotation(lineExplanation)
+		annotationWidth = max(annotationWidth, len(annotations[lineIndex]))
+	}
+
+	fmt.Fprintf(outputWriter, "==> %s <==\n", filename)
+
+	for lineIndex, lineExplanation := range lineExplanations {
+		fmt.Fprintf(outputWriter, "%*d  %-*s | %s\n", lineNumberWidth, lineExplanation.Line, annotationWidth, annotations[lineIndex], lineExplanation.Content)
+	}
+
+	return fileUnchanged, nil
+}
+
+func explanationAnnotation(lineExplanation iku.LineExplanation) string {
+	var annotationParts []string
+
+	switch {
+	case lineExplanation.Blank == "inserted" || lineExplanation.Blank == "kept":
+		return fmt.Sprintf("blank %s (%s)", lineExplanation.Blank, lineExplanation.Rule)
+	case lineExplanation.Blank != "":
+		return "blank " + lineExplanation.Blank
+	case lineExplanation.IsComment:
+		annotationParts = append(annotationParts, "comment")
+	case lineExplanation.StatementType != "":
+		annotationParts = append(annotationParts, lineExplanation.StatementType)
+	}
+
+	for _, flag := range []struct {
+		isSet bool
+		name  string
+	}{
+		{lineExplanation.IsTopLevel, "top-level"},
+		{lineExplanation.IsScoped, "scoped"},
+		{lineExplanation.IsContinuation, "continuation"},
+	} {
+		if flag.isSet {
+			annotationParts = append(annotationParts, flag.name)
+		}
+	}
+
+	if lineExplanation.RemovedBlankLines > 0 {
+		annotationParts = append(annotationParts, fmt.Sprintf("%d blank removed before (%s)", lineExplanation.RemovedBlankLines, lineExplanation.Rule))
+	}
+
+	return strings.Join(annotationParts, ", ")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Fuwn/iku/engine"
+	"github.com/Fuwn/iku/iku"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func runGit(workingDirectory string, standardInput []byte, arguments ...string) ([]byte, error) {
+	var standardOutput, standardError bytes.Buffer
+
+	command := exec.Command("git", arguments...)
+	command.Dir = workingDirectory
+	command.Stdout = &standardOutput
+	command.Stderr = &standardError
+
+	if standardInput != nil {
+		command.Stdin = bytes.NewReader(standardInput)
+	}
+
+	if err := command.Run(); err != nil {
+		if errorMessage := strings.TrimSpace(standardError.String()); errorMessage != "" {
+			return nil, fmt.Errorf("git %s: %s", arguments[0], errorMessage)
+		}
+
+		return nil, fmt.Errorf("git %s: %v", arguments[0], err)
+	}
+
+	return standardOutput.Bytes(), nil
+}
+
+func gitChangedLineRanges(workingDirectory string, diffBase string, pathArguments []string) (map[string][]engine.LineRange, error) {
+	diffContent, err := runGit(workingDirectory, nil, append([]string{"diff", "-U0", "--no-color", "--no-ext-diff", "--relative", "--src-prefix=a/", "--dst-prefix=b/", diffBase, "--"}, pathArguments...)...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDiffLineRanges(diffContent)
+}
+
+func processStagedFiles(options iku.Options, summary *runSummary) error {
+	topLevelOutput, err := runGit("", nil, "rev-parse", "--show-toplevel")
+
+	if err != nil {
+		return err
+	}
+
+	repositoryRoot := strings.TrimSpace(string(topLevelOutput))
+	stagedNamesOutput, err := runGit(repositoryRoot, nil, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+
+	if err != nil {
+		return err
+	}
+
+	for _, stagedPath := range strings.Split(string(stagedNamesOutput), "\x00") {
+		if stagedPath == "" {
+			continue
+		}
+
+		fileOptions, err := configurations.resolveOptions(options, filepath.Join(repositoryRoot, filepath.FromSlash(stagedPath)))
+
+		if err != nil {
+			summary.recordError(err)
+
+			continue
+		}
+
+		if !fileOptions.Configuration.IsSupportedFile(stagedPath) {
+			continue
+		}
+
+		status, err := processStagedFile(fileOptions, repositoryRoot, stagedPath)
+
+		summary.record(stagedPath, status, err)
+	}
+
+	return nil
+}
+
+func processStagedFile(options iku.Options, repositoryRoot string, stagedPath string) (fileStatus, error) {
+	indexEntryOutput, err := runGit(repositoryRoot, nil, "ls-files", "--stage", "-z", "--", stagedPath)
+
+	if err != nil {
+		return fileUnchanged, err
+	}
+
+	indexEntry, _, _ := strings.Cut(string(indexEntryOutput), "\t")
+	indexEntryFields := strings.Fields(indexEntry)
+
+	if len(indexEntryFields) != 3 {
+		return fileUnchanged, fmt.Errorf("%s: unexpected index entry: %q", stagedPath, indexEntry)
+	}
+
+	fileMode, objectName := indexEntryFields[0], indexEntryFields[1]
+
+	if fileMode != "100644" && fileMode != "100755" {
+		return fileUnchanged, nil
+	}
+
+	stagedContent, err := runGit(repositoryRoot, nil, "cat-file", "blob", objectName)
+
+	if err != nil {
+		return fileUnchanged, err
+	}
+
+	if *listFlag || *checkFlag || *diffFlag || lintMode || *explainFlag {
+		return processFile(options, stagedPath, bytes.NewReader(stagedContent), os.Stdout, false)
+	}
+
+	if !*includeGeneratedFlag && iku.IsGenerated(stagedContent, stagedPath) {
+		return fileSkippedGenerated, nil
+	}
+
+	formattedResult, err := iku.Format(stagedContent, stagedPath, options)
+
+	if err != nil {
+		return fileUnchanged, err
+	}
+
+	if bytes.Equal(stagedContent, formattedResult) {
+		return fileUnchanged, nil
+	}
+
+	formattedObjectName, err := runGit(repositoryRoot, formattedResult, "hash-object", "-w", "--stdin", "--no-filters")
+
+	if err != nil {
+		return fileUnchanged, err
+	}
+
+	indexInformation := fmt.Sprintf("%s,%s,%s", fileMode, strings.TrimSpace(string(formattedObjectName)), stagedPath)
+
+	if _, err := runGit(repositoryRoot, nil, "update-index", "--cacheinfo", indexInformation); err != nil {
+		return fileUnchanged, err
+	}
+
+	workingTreePath := filepath.Join(repositoryRoot, filepath.FromSlash(stagedPath))
+
+	if workingTreeContent, err := os.ReadFile(workingTreePath); err == nil && bytes.Equal(workingTreeContent, stagedContent) {
+		fileInformation, err := os.Stat(workingTreePath)
+
+		if err != nil {
+			return fileReformatted, err
+		}
+
+		if err := os.WriteFile(workingTreePath, formattedResult, fileInformation.Mode().Perm()); err != nil {
+			return fileReformatted, err
+		}
+	}
+
+	fmt.Println(stagedPath)
+
+	return fileReformatted, nil
+}
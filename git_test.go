@@ -0,0 +1,56 @@
+package main
+
+import (
+	"github.com/Fuwn/iku/engine"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGitChangedLineRangesIgnoresPrefixConfiguration(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	for _, prefixConfiguration := range []string{"diff.mnemonicPrefix", "diff.noprefix"} {
+		t.Run(prefixConfiguration, func(t *testing.T) {
+			repositoryDirectory := t.TempDir()
+			sourcePath := filepath.Join(repositoryDirectory, "b", "m.go")
+
+			if err := os.MkdirAll(filepath.Dir(sourcePath), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(sourcePath, []byte("package m\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, gitArguments := range [][]string{
+				{"init", "-q"},
+				{"config", prefixConfiguration, "true"},
+				{"add", "."},
+				{"-c", "user.name=iku", "-c", "user.email=iku@example.com", "commit", "-q", "-m", "initial"},
+			} {
+				if _, err := runGit(repositoryDirectory, nil, gitArguments...); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := os.WriteFile(sourcePath, []byte("package m\n\nvar x = 1\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			changedLineRanges, err := gitChangedLineRanges(repositoryDirectory, "HEAD", nil)
+
+			if err != nil {
+				t.Fatalf("diff error: %v", err)
+			}
+
+			if expectedLineRanges := map[string][]engine.LineRange{filepath.FromSlash("b/m.go"): {{Start: 2, End: 3}}}; !reflect.DeepEqual(changedLineRanges, expectedLineRanges) {
+				t.Errorf("got %v, want %v", changedLineRanges, expectedLineRanges)
+			}
+		})
+	}
+}
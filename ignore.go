@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"github.com/Fuwn/iku/internal/pathpattern"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var ignoreFileNames = []string{".gitignore", ".ikuignore"}
+var defaultIgnorePatterns = []string{"node_modules/", "vendor/"}
+
+type ignorePattern struct {
+	baseDirectory   string
+	expression      *regexp.Regexp
+	isNegated       bool
+	isDirectoryOnly bool
+}
+
+type ignoreMatcher struct {
+	patterns []ignorePattern
+}
+
+func newIgnoreMatcher(rootDirectory string, excludePatterns []string) (*ignoreMatcher, error) {
+	absoluteRootDirectory, err := filepath.Abs(rootDirectory)
+
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := &ignoreMatcher{}
+	baseDirectory := filepath.ToSlash(absoluteRootDirectory)
+
+	for _, patternLine := range defaultIgnorePatterns {
+		matcher.addPattern(baseDirectory, patternLine)
+	}
+
+	for _, ancestorDirectory := range ignoreAncestorDirectories(absoluteRootDirectory) {
+		if err := matcher.addIgnoreFiles(ancestorDirectory); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, patternLine := range excludePatterns {
+		matcher.addPattern(baseDirectory, patternLine)
+	}
+
+	return matcher, nil
+}
+
+func isRepositoryRoot(directoryPath string) bool {
+	_, err := os.Stat(filepath.Join(directoryPath, ".git"))
+
+	return err == nil
+}
+
+func ignoreAncestorDirectories(absoluteDirectory string) []string {
+	if isRepositoryRoot(absoluteDirectory) {
+		return nil
+	}
+
+	var ancestorDirectories []string
+
+	for currentDirectory := filepath.Dir(absoluteDirectory); ; currentDirectory = filepath.Dir(currentDirectory) {
+		ancestorDirectories = append([]string{currentDirectory}, ancestorDirectories...)
+
+		if isRepositoryRoot(currentDirectory) {
+			return ancestorDirectories
+		}
+
+		if filepath.Dir(currentDirectory) == currentDirectory {
+			return nil
+		}
+	}
+}
+
+func (m *ignoreMatcher) withDirectory(absoluteDirectory string) (*ignoreMatcher, error) {
+	childMatcher := &ignoreMatcher{patterns: m.patterns[:len(m.patterns):len(m.patterns)]}
+
+	if err := childMatcher.addIgnoreFiles(absoluteDirectory); err != nil {
+		return nil, err
+	}
+
+	return childMatcher, nil
+}
+
+func (m *ignoreMatcher) addIgnoreFiles(absoluteDirectory string) error {
+	baseDirectory := filepath.ToSlash(absoluteDirectory)
+
+	for _, ignoreFileName := range ignoreFileNames {
+		ignoreFile, err := os.Open(filepath.Join(absoluteDirectory, ignoreFileName))
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		lineScanner := bufio.NewScanner(ignoreFile)
+
+		for lineScanner.Scan() {
+			m.addPattern(baseDirectory, lineScanner.Text())
+		}
+
+		scanError := lineScanner.Err()
+		_ = ignoreFile.Close()
+
+		if scanError != nil {
+			return scanError
+		}
+	}
+
+	return nil
+}
+
+func (m *ignoreMatcher) addPattern(baseDirectory string, patternLine string) {
+	patternLine = strings.TrimSuffix(patternLine, "\r")
+
+	if !strings.HasSuffix(patternLine, "\\ ") {
+		patternLine = strings.TrimRight(patternLine, " ")
+	}
+
+	if patternLine == "" || strings.HasPrefix(patternLine, "#") {
+		return
+	}
+
+	pattern := ignorePattern{baseDirectory: baseDirectory}
+
+	if strings.HasPrefix(patternLine, "!") {
+		pattern.isNegated = true
+		patternLine = patternLine[1:]
+	}
+
+	if strings.HasSuffix(patternLine, "/") {
+		pattern.isDirectoryOnly = true
+		patternLine = strings.TrimRight(patternLine, "/")
+	}
+
+	if patternLine == "" {
+		return
+	}
+
+	expression, err := pathpattern.Compile(patternLine)
+
+	if err != nil {
+		return
+	}
+
+	pattern.expression = expression
+	m.patterns = append(m.patterns, pattern)
+}
+
+func (m *ignoreMatcher) isIgnored(absolutePath string, isDirectory bool) bool {
+	slashPath := filepath.ToSlash(absolutePath)
+	isIgnored := false
+
+	for _, pattern := range m.patterns {
+		if pattern.isDirectoryOnly && !isDirectory {
+			continue
+		}
+
+		if isIgnored != pattern.isNegated {
+			continue
+		}
+
+		relativePath, isUnderBase := strings.CutPrefix(slashPath, pattern.baseDirectory+"/")
+
+		if !isUnderBase {
+			continue
+		}
+
+		if pattern.expression.MatchString(relativePath) {
+			isIgnored = !pattern.isNegated
+		}
+	}
+
+	return isIgnored
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcherPatterns(t *testing.T) {
+	matcher := &ignoreMatcher{}
+
+	for _, patternLine := range []string{"# comment", "*.gen.go", "/build", "dist/", "docs/**/*.ts", "!keep.gen.go", "a?c.js", "[xy].ts"} {
+		matcher.addPattern("/repository", patternLine)
+	}
+
+	cases := []struct {
+		path            string
+		isDirectory     bool
+		expectedIgnored bool
+	}{
+		{"/repository/main.go", false, false},
+		{"/repository/api/types.gen.go", false, true},
+		{"/repository/api/keep.gen.go", false, false},
+		{"/repository/build", true, true},
+		{"/repository/cmd/build", true, false},
+		{"/repository/web/dist", true, true},
+		{"/repository/web/dist", false, false},
+		{"/repository/docs/index.ts", false, true},
+		{"/repository/docs/guide/deep/index.ts", false, true},
+		{"/repository/src/docs/index.ts", false, false},
+		{"/repository/abc.js", false, true},
+		{"/repository/abbc.js", false, false},
+		{"/repository/x.ts", false, true},
+		{"/repository/z.ts", false, false},
+		{"/elsewhere/types.gen.go", false, false},
+	}
+
+	for _, testCase := range cases {
+		if isIgnored := matcher.isIgnored(testCase.path, testCase.isDirectory); isIgnored != testCase.expectedIgnored {
+			t.Errorf("isIgnored(%q, %v) = %v, want %v", testCase.path, testCase.isDirectory, isIgnored, testCase.expectedIgnored)
+		}
+	}
+}
+
+func TestIgnoreMatcherNestedFiles(t *testing.T) {
+	rootDirectory := t.TempDir()
+
+	for filePath, fileContent := range map[string]string{
+		".gitignore":         "*.out.ts\n",
+		"web/.ikuignore":     "legacy/\n!special.out.ts\n",
+		"web/special.out.ts": "",
+	} {
+		absolutePath := filepath.Join(rootDirectory, filePath)
+
+		if err := os.MkdirAll(filepath.Dir(absolutePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(absolutePath, []byte(fileContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootMatcher, err := newIgnoreMatcher(rootDirectory, []string{"*.skip.js"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootMatcher, err = rootMatcher.withDirectory(rootDirectory)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webMatcher, err := rootMatcher.withDirectory(filepath.Join(rootDirectory, "web"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		matcher         *ignoreMatcher
+		path            string
+		isDirectory     bool
+		expectedIgnored bool
+	}{
+		{rootMatcher, "node_modules", true, true},
+		{rootMatcher, "vendor", true, true},
+		{rootMatcher, "main.out.ts", false, true},
+		{rootMatcher, "main.skip.js", false, true},
+		{rootMatcher, "legacy", true, false},
+		{webMatcher, "web/legacy", true, true},
+		{webMatcher, "web/special.out.ts", false, false},
+		{webMatcher, "web/other.out.ts", false, true},
+	}
+
+	for _, testCase := range cases {
+		absolutePath := filepath.Join(rootDirectory, testCase.path)
+
+		if isIgnored := testCase.matcher.isIgnored(absolutePath, testCase.isDirectory); isIgnored != testCase.expectedIgnored {
+			t.Errorf("isIgnored(%q, %v) = %v, want %v", testCase.path, testCase.isDirectory, isIgnored, testCase.expectedIgnored)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package iku
+
+import (
+	"bytes"
+	"github.com/Fuwn/iku/engine"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type Adapter interface {
+	Name() string
+	Extensions() []string
+	LanguageIdentifiers() []string
+	Interpreters() []string
+	Analyze(source []byte) ([]byte, []engine.LineEvent, error)
+}
+
+type GeneratedSourceDetector interface {
+	IsGenerated(source []byte) bool
+}
+
+var (
+	adapterRegistryMutex sync.RWMutex
+	registeredAdapters   []Adapter
+)
+
+func init() {
+	RegisterAdapter(&GoAdapter{})
+	RegisterAdapter(&EcmaScriptAdapter{})
+}
+
+func RegisterAdapter(adapter Adapter) {
+	adapterRegistryMutex.Lock()
+
+	defer adapterRegistryMutex.Unlock()
+
+	registeredAdapters = slices.DeleteFunc(registeredAdapters, func(registeredAdapter Adapter) bool {
+		return registeredAdapter.Name() == adapter.Name()
+	})
+	registeredAdapters = append(registeredAdapters, adapter)
+}
+
+func Adapters() []Adapter {
+	adapterRegistryMutex.RLock()
+
+	defer adapterRegistryMutex.RUnlock()
+
+	return slices.Clone(registeredAdapters)
+}
+
+func findAdapter(matches func(adapter Adapter) bool) Adapter {
+	adapterRegistryMutex.RLock()
+
+	defer adapterRegistryMutex.RUnlock()
+
+	for adapterIndex := len(registeredAdapters) - 1; adapterIndex >= 0; adapterIndex-- {
+		if matches(registeredAdapters[adapterIndex]) {
+			return registeredAdapters[adapterIndex]
+		}
+	}
+
+	return nil
+}
+
+func AdapterForFilename(filename string) Adapter {
+	fileExtension := filepath.Ext(filename)
+
+	if fileExtension == "" {
+		return nil
+	}
+
+	return findAdapter(func(adapter Adapter) bool {
+		return slices.Contains(adapter.Extensions(), fileExtension)
+	})
+}
+
+func AdapterForLanguage(languageIdentifier string) Adapter {
+	if languageIdentifier == "" {
+		return nil
+	}
+
+	return findAdapter(func(adapter Adapter) bool {
+		return slices.Contains(adapter.LanguageIdentifiers(), languageIdentifier)
+	})
+}
+
+func AdapterForShebang(source []byte) Adapter {
+	interpreterName := shebangInterpreter(source)
+
+	if interpreterName == "" {
+		return nil
+	}
+
+	return findAdapter(func(adapter Adapter) bool {
+		return slices.Contains(adapter.Interpreters(), interpreterName)
+	})
+}
+
+func SelectAdapter(source []byte, filename string, languageIdentifier string) Adapter {
+	if adapter := AdapterForLanguage(languageIdentifier); adapter != nil {
+		return adapter
+	}
+
+	if adapter := AdapterForFilename(filename); adapter != nil {
+		return adapter
+	}
+
+	if adapter := AdapterForShebang(source); adapter != nil {
+		return adapter
+	}
+
+	return &GoAdapter{}
+}
+
+func IsSupportedFile(filename string) bool {
+	return AdapterForFilename(filename) != nil
+}
+
+func shebangInterpreter(source []byte) string {
+	if !bytes.HasPrefix(source, []byte("#!")) {
+		return ""
+	}
+
+	firstLine, _, _ := bytes.Cut(source[2:], []byte("\n"))
+	shebangFields := strings.Fields(string(firstLine))
+
+	if len(shebangFields) == 0 {
+		return ""
+	}
+
+	interpreterName := filepath.Base(shebangFields[0])
+
+	if interpreterName != "env" {
+		return interpreterName
+	}
+
+	for _, shebangField := range shebangFields[1:] {
+		if !strings.HasPrefix(shebangField, "-") && !strings.Contains(shebangField, "=") {
+			return filepath.Base(shebangField)
+		}
+	}
+
+	return ""
+}
+
+func configurationLanguage(adapter Adapter, filename string, languageIdentifier string) string {
+	switch adapter.(type) {
+	case *GoAdapter:
+		return "go"
+	case *EcmaScriptAdapter:
+		switch {
+		case languageIdentifier == "typescript" || languageIdentifier == "typescriptreact":
+			return "typescript"
+		case languageIdentifier == "javascript" || languageIdentifier == "javascriptreact":
+			return "javascript"
+		case filepath.Ext(filename) == ".ts" || filepath.Ext(filename) == ".tsx":
+			return "typescript"
+		default:
+			return "javascript"
+		}
+	default:
+		return ""
+	}
+}
@@ -1,12 +1,30 @@
-package main
+package iku
 
 import (
 	"github.com/Fuwn/iku/engine"
 	"strings"
 )
 
+var ecmaScriptScopeKinds = []string{"function", "class", "if", "for", "while", "do", "switch", "try", "interface", "enum", "namespace"}
+
 type EcmaScriptAdapter struct{}
 
+func (a *EcmaScriptAdapter) Name() string {
+	return "ecmascript"
+}
+
+func (a *EcmaScriptAdapter) Extensions() []string {
+	return []string{".js", ".ts", ".jsx", ".tsx"}
+}
+
+func (a *EcmaScriptAdapter) LanguageIdentifiers() []string {
+	return []string{"javascript", "typescript", "javascriptreact", "typescriptreact"}
+}
+
+func (a *EcmaScriptAdapter) Interpreters() []string {
+	return []string{"node", "nodejs", "deno", "bun", "ts-node", "tsx"}
+}
+
 func (a *EcmaScriptAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent, error) {
 	sourceLines := strings.Split(string(source), "\n")
 	events := make([]engine.LineEvent, len(sourceLines))
@@ -14,6 +32,8 @@ func (a *EcmaScriptAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent,
 	insideBlockComment := false
 	previousEndedWithContinuation := false
 
+	var scopeKindStack []string
+
 	for lineIndex, currentLine := range sourceLines {
 		backtickCount := countRawStringDelimiters(currentLine)
 		wasInsideTemplateString := insideTemplateString
@@ -83,6 +103,7 @@ func (a *EcmaScriptAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent,
 		previousEndedWithContinuation = ecmaScriptLineEndsContinuation(trimmedContent)
 
 		if isClosingCurlyBrace(currentLine) {
+			scopeKindStack, event.ScopeKind = trackEcmaScriptScopeKinds(scopeKindStack, currentLine, "")
 			event.HasASTInfo = true
 			event.IsScoped = true
 			event.IsTopLevel = ecmaScriptLineIsTopLevel(currentLine)
@@ -92,6 +113,7 @@ func (a *EcmaScriptAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent,
 		}
 
 		if isContinuationLine {
+			scopeKindStack, _ = trackEcmaScriptScopeKinds(scopeKindStack, currentLine, "")
 			events[lineIndex] = event
 
 			continue
@@ -106,15 +128,22 @@ func (a *EcmaScriptAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent,
 			event.IsContinuation = isContinuation
 			event.IsTopLevel = ecmaScriptLineIsTopLevel(currentLine)
 			event.IsStartLine = true
+
+			if isScoped {
+				event.ScopeKind = statementType
+			}
 		} else {
 			event.HasASTInfo = true
 			event.StatementType = "expression"
 			event.IsTopLevel = ecmaScriptLineIsTopLevel(currentLine)
 		}
 
+		scopeKindStack, _ = trackEcmaScriptScopeKinds(scopeKindStack, currentLine, event.ScopeKind)
 		events[lineIndex] = event
 	}
 
+	applyFormattingDirectives(events, findBracketedStatementEnd)
+
 	return source, events, nil
 }
 
@@ -182,6 +211,31 @@ func classifyEcmaScriptStatement(trimmedLine string) (string, bool, bool) {
 	return "", false, false
 }
 
+func trackEcmaScriptScopeKinds(scopeKindStack []string, sourceLine string, scopeKind string) ([]string, string) {
+	closedScopeKind := ""
+	hasClosedScope := false
+
+	scanCodeCharacters(sourceLine, func(character byte) {
+		switch {
+		case character == '{':
+			if scopeKind == "" && hasClosedScope {
+				scopeKind = closedScopeKind
+			}
+
+			scopeKindStack = append(scopeKindStack, scopeKind)
+		case character == '}' && len(scopeKindStack) > 0:
+			if !hasClosedScope {
+				closedScopeKind = scopeKindStack[len(scopeKindStack)-1]
+				hasClosedScope = true
+			}
+
+			scopeKindStack = scopeKindStack[:len(scopeKindStack)-1]
+		}
+	})
+
+	return scopeKindStack, closedScopeKind
+}
+
 func ecmaScriptStatementHasPrefix(line string, keyword string) bool {
 	if !strings.HasPrefix(line, keyword) {
 		return false
@@ -1,4 +1,4 @@
-package main
+package iku
 
 import (
 	"github.com/Fuwn/iku/engine"
@@ -385,6 +385,40 @@ enum Direction {
 }
 
 const x = Direction.Up;
+`,
+	},
+	{
+		name: "formatting directives",
+		source: `const a = 1;
+// iku:ignore
+const table = [
+  1,
+
+  2,
+];
+// iku:off
+const x = 1;
+
+const y = 2;
+// iku:on
+let z = 3;
+
+let w = 4;
+`,
+		expected: `const a = 1;
+// iku:ignore
+const table = [
+  1,
+
+  2,
+];
+// iku:off
+const x = 1;
+
+const y = 2;
+// iku:on
+let z = 3;
+let w = 4;
 `,
 	},
 }
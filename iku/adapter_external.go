@@ -0,0 +1,193 @@
+package iku
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Fuwn/iku/engine"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const defaultExternalAdapterTimeout = 10 * time.Second
+const externalAdapterProtocolVersion = 1
+
+type ExternalAdapterConfiguration struct {
+	Command []string `json:"command"`
+	Timeout string   `json:"timeout"`
+}
+
+type ExternalAdapter struct {
+	Extension string
+	Command   []string
+	Timeout   time.Duration
+}
+
+type AdapterError struct {
+	Command string
+	Err     error
+}
+
+func (e *AdapterError) Error() string {
+	return fmt.Sprintf("external adapter %q: %v", e.Command, e.Err)
+}
+
+func (e *AdapterError) Unwrap() error {
+	return e.Err
+}
+
+type externalAdapterRequest struct {
+	Version  int    `json:"version"`
+	Filename string `json:"filename"`
+	Source   string `json:"source"`
+}
+
+type externalAdapterEvent struct {
+	Content        string `json:"content"`
+	StatementType  string `json:"statement_type"`
+	IsTopLevel     bool   `json:"is_top_level"`
+	IsScoped       bool   `json:"is_scoped"`
+	ScopeKind      string `json:"scope_kind"`
+	IsStartLine    bool   `json:"is_start_line"`
+	HasASTInfo     bool   `json:"has_ast_info"`
+	IsClosingBrace bool   `json:"is_closing_brace"`
+	IsOpeningBrace bool   `json:"is_opening_brace"`
+	IsCaseLabel    bool   `json:"is_case_label"`
+	IsContinuation bool   `json:"is_continuation"`
+	IsCommentOnly  bool   `json:"is_comment_only"`
+	InRawString    bool   `json:"in_raw_string"`
+	IsPackageDecl  bool   `json:"is_package_decl"`
+	IsVerbatim     bool   `json:"is_verbatim"`
+}
+
+type externalAdapterResponse struct {
+	Events []externalAdapterEvent `json:"events"`
+	Error  string                 `json:"error"`
+}
+
+func newExternalAdapter(extension string, adapterConfiguration ExternalAdapterConfiguration) (*ExternalAdapter, error) {
+	if !strings.HasPrefix(extension, ".") {
+		return nil, fmt.Errorf("adapters: extension %q must start with \".\"", extension)
+	}
+
+	if len(adapterConfiguration.Command) == 0 || adapterConfiguration.Command[0] == "" {
+		return nil, fmt.Errorf("adapters: %s: command must not be empty", extension)
+	}
+
+	adapterTimeout := defaultExternalAdapterTimeout
+
+	if adapterConfiguration.Timeout != "" {
+		parsedTimeout, err := time.ParseDuration(adapterConfiguration.Timeout)
+
+		if err != nil || parsedTimeout <= 0 {
+			return nil, fmt.Errorf("adapters: %s: invalid timeout %q (use a duration such as \"5s\")", extension, adapterConfiguration.Timeout)
+		}
+
+		adapterTimeout = parsedTimeout
+	}
+
+	return &ExternalAdapter{Extension: extension, Command: adapterConfiguration.Command, Timeout: adapterTimeout}, nil
+}
+
+func (a *ExternalAdapter) Name() string {
+	return "external:" + a.Extension
+}
+
+func (a *ExternalAdapter) Extensions() []string {
+	return []string{a.Extension}
+}
+
+func (a *ExternalAdapter) LanguageIdentifiers() []string {
+	return nil
+}
+
+func (a *ExternalAdapter) Interpreters() []string {
+	return nil
+}
+
+func (a *ExternalAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent, error) {
+	return a.analyze(source, "")
+}
+
+func (a *ExternalAdapter) analyze(source []byte, filename string) ([]byte, []engine.LineEvent, error) {
+	requestContent, err := json.Marshal(externalAdapterRequest{Version: externalAdapterProtocolVersion, Filename: filename, Source: string(source)})
+
+	if err != nil {
+		return nil, nil, a.adapterError(err)
+	}
+
+	var standardOutput, standardError bytes.Buffer
+
+	timeoutContext, cancel := context.WithTimeout(context.Background(), a.Timeout)
+
+	defer cancel()
+
+	command := exec.CommandContext(timeoutContext, a.Command[0], a.Command[1:]...)
+	command.Stdin = bytes.NewReader(requestContent)
+	command.Stdout = &standardOutput
+	command.Stderr = &standardError
+	command.WaitDelay = time.Second
+
+	if err := command.Run(); err != nil {
+		var exitError *exec.ExitError
+
+		switch errorMessage := strings.TrimSpace(standardError.String()); {
+		case errors.Is(timeoutContext.Err(), context.DeadlineExceeded):
+			return nil, nil, a.adapterError(fmt.Errorf("timed out after %s", a.Timeout))
+		case errors.As(err, &exitError) && errorMessage != "":
+			return nil, nil, a.adapterError(fmt.Errorf("exited with status %d: %s", exitError.ExitCode(), errorMessage))
+		default:
+			return nil, nil, a.adapterError(err)
+		}
+	}
+
+	var response externalAdapterResponse
+
+	if err := json.Unmarshal(standardOutput.Bytes(), &response); err != nil {
+		return nil, nil, a.adapterError(fmt.Errorf("invalid response: %v", err))
+	}
+
+	if response.Error != "" {
+		return nil, nil, errors.New(response.Error)
+	}
+
+	if len(response.Events) == 0 && len(bytes.TrimSpace(source)) > 0 {
+		return nil, nil, a.adapterError(errors.New("invalid response: no events for non-empty source"))
+	}
+
+	eventContents := make([]string, len(response.Events))
+	events := make([]engine.LineEvent, len(response.Events))
+
+	for eventIndex, responseEvent := range response.Events {
+		if strings.Contains(responseEvent.Content, "\n") {
+			return nil, nil, a.adapterError(fmt.Errorf("invalid response: event %d content contains a newline", eventIndex))
+		}
+
+		event := engine.NewLineEvent(responseEvent.Content)
+		event.StatementType = responseEvent.StatementType
+		event.IsTopLevel = responseEvent.IsTopLevel
+		event.IsScoped = responseEvent.IsScoped
+		event.ScopeKind = responseEvent.ScopeKind
+		event.IsStartLine = responseEvent.IsStartLine
+		event.HasASTInfo = responseEvent.HasASTInfo
+		event.IsClosingBrace = responseEvent.IsClosingBrace
+		event.IsOpeningBrace = responseEvent.IsOpeningBrace
+		event.IsCaseLabel = responseEvent.IsCaseLabel
+		event.IsContinuation = responseEvent.IsContinuation
+		event.IsCommentOnly = responseEvent.IsCommentOnly
+		event.InRawString = responseEvent.InRawString
+		event.IsPackageDecl = responseEvent.IsPackageDecl
+		event.IsVerbatim = responseEvent.IsVerbatim
+		events[eventIndex] = event
+		eventContents[eventIndex] = responseEvent.Content
+	}
+
+	return []byte(strings.Join(eventContents, "\n")), events, nil
+}
+
+func (a *ExternalAdapter) adapterError(err error) error {
+	return &AdapterError{Command: strings.Join(a.Command, " "), Err: err}
+}
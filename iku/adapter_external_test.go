@@ -0,0 +1,134 @@
+package iku
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExternalAdapterHelperProcess(t *testing.T) {
+	helperMode := os.Getenv("IKU_EXTERNAL_ADAPTER_HELPER")
+
+	if helperMode == "" {
+		return
+	}
+
+	var request externalAdapterRequest
+
+	_ = json.NewDecoder(os.Stdin).Decode(&request)
+
+	switch helperMode {
+	case "lines":
+		var events []externalAdapterEvent
+
+		for _, sourceLine := range strings.Split(request.Source, "\n") {
+			statementType, _, _ := strings.Cut(strings.TrimSpace(sourceLine), " ")
+			events = append(events, externalAdapterEvent{Content: sourceLine, StatementType: statementType, IsTopLevel: true, HasASTInfo: sourceLine != ""})
+		}
+
+		_ = json.NewEncoder(os.Stdout).Encode(externalAdapterResponse{Events: events})
+	case "reject":
+		_ = json.NewEncoder(os.Stdout).Encode(externalAdapterResponse{Error: "line 1: unexpected token"})
+	case "garbage":
+		fmt.Print("not json")
+	case "crash":
+		fmt.Fprint(os.Stderr, "adapter exploded")
+		os.Exit(3)
+	case "hang":
+		time.Sleep(time.Minute)
+	}
+
+	os.Exit(0)
+}
+
+func externalAdapterTestConfiguration(t *testing.T, helperMode string, timeout string) Configuration {
+	t.Setenv("IKU_EXTERNAL_ADAPTER_HELPER", helperMode)
+
+	return Configuration{Adapters: map[string]ExternalAdapterConfiguration{
+		".dsl": {Command: []string{os.Args[0], "-test.run=^TestExternalAdapterHelperProcess$"}, Timeout: timeout},
+	}}
+}
+
+func TestExternalAdapterFormat(t *testing.T) {
+	configuration := externalAdapterTestConfiguration(t, "lines", "")
+
+	if !configuration.IsSupportedFile("rules.dsl") {
+		t.Error("expected configured extension to be supported")
+	}
+
+	formattedResult, err := Format([]byte("rule a\nrule b\nset x\n"), "rules.dsl", Options{Configuration: configuration})
+
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expectedOutput := "rule a\nrule b\n\nset x\n"
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestExternalAdapterErrors(t *testing.T) {
+	testCases := []struct {
+		helperMode    string
+		timeout       string
+		expectedError string
+		isParseError  bool
+	}{
+		{"reject", "", "rules.dsl: line 1: unexpected token", true},
+		{"garbage", "", "invalid response", false},
+		{"crash", "", "exited with status 3: adapter exploded", false},
+		{"hang", "100ms", "timed out after 100ms", false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.helperMode, func(t *testing.T) {
+			var parseError *ParseError
+			var adapterError *AdapterError
+
+			configuration := externalAdapterTestConfiguration(t, testCase.helperMode, testCase.timeout)
+			_, err := Format([]byte("rule a\n"), "rules.dsl", Options{Configuration: configuration})
+
+			if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+				t.Fatalf("got error %v, want it to contain %q", err, testCase.expectedError)
+			}
+
+			if errors.As(err, &parseError) != testCase.isParseError {
+				t.Errorf("got parse error = %v, want %v", !testCase.isParseError, testCase.isParseError)
+			}
+
+			if !testCase.isParseError && !errors.As(err, &adapterError) {
+				t.Errorf("expected *AdapterError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestExternalAdapterConfigurationValidation(t *testing.T) {
+	testCases := []struct {
+		name          string
+		adapters      map[string]ExternalAdapterConfiguration
+		expectedError string
+	}{
+		{"missing dot", map[string]ExternalAdapterConfiguration{"dsl": {Command: []string{"adapter"}}}, `extension "dsl" must start with "."`},
+		{"empty command", map[string]ExternalAdapterConfiguration{".dsl": {}}, ".dsl: command must not be empty"},
+		{"bad timeout", map[string]ExternalAdapterConfiguration{".dsl": {Command: []string{"adapter"}, Timeout: "soon"}}, `.dsl: invalid timeout "soon"`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var configurationError *ConfigurationError
+
+			_, err := Format([]byte("rule a\n"), "rules.dsl", Options{Configuration: Configuration{Adapters: testCase.adapters}})
+
+			if !errors.As(err, &configurationError) || !strings.Contains(err.Error(), testCase.expectedError) {
+				t.Errorf("got error %v, want *ConfigurationError containing %q", err, testCase.expectedError)
+			}
+		})
+	}
+}
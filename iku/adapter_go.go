@@ -1,15 +1,40 @@
-package main
+package iku
 
 import (
 	"bytes"
 	"github.com/Fuwn/iku/engine"
+	"go/ast"
 	"go/format"
 	"go/parser"
 	"go/token"
+	"slices"
 )
 
+var goScopeKinds = []string{"if", "for", "range", "switch", "type_switch", "select", "select_single_case", "block", "struct", "interface", "func", "defer_func", "go_func"}
+var defaultGoScopedKinds = []string{"if", "for", "range", "switch", "type_switch", "select", "select_single_case", "block", "struct", "interface", "func"}
+
 type GoAdapter struct{}
 
+func (a *GoAdapter) Name() string {
+	return "go"
+}
+
+func (a *GoAdapter) Extensions() []string {
+	return []string{".go"}
+}
+
+func (a *GoAdapter) LanguageIdentifiers() []string {
+	return []string{"go"}
+}
+
+func (a *GoAdapter) Interpreters() []string {
+	return nil
+}
+
+func (a *GoAdapter) IsGenerated(source []byte) bool {
+	return isGeneratedGoSource(source)
+}
+
 func (a *GoAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent, error) {
 	formattedSource, err := format.Source(source)
 
@@ -62,6 +87,7 @@ func (a *GoAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent, error) {
 			event.StatementType = currentInformation.statementType
 			event.IsTopLevel = currentInformation.isTopLevel
 			event.IsScoped = currentInformation.isScoped
+			event.ScopeKind = currentInformation.scopeKind
 			event.IsStartLine = currentInformation.isStartLine
 		}
 
@@ -73,9 +99,31 @@ func (a *GoAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent, error) {
 		events[lineIndex] = event
 	}
 
+	applyFormattingDirectives(events, func(events []engine.LineEvent, startIndex int) int {
+		if startInformation := lineInformationMap[startIndex+1]; startInformation != nil && startInformation.isStartLine {
+			return startInformation.endLine - 1
+		}
+
+		return findBracketedStatementEnd(events, startIndex)
+	})
+
 	return formattedSource, events, nil
 }
 
+func isDefaultGoScopeKind(scopeKind string) bool {
+	return slices.Contains(defaultGoScopedKinds, scopeKind)
+}
+
+func isGeneratedGoSource(source []byte) bool {
+	parsedFile, err := parser.ParseFile(token.NewFileSet(), "", source, parser.PackageClauseOnly|parser.ParseComments)
+
+	if err != nil {
+		return false
+	}
+
+	return ast.IsGenerated(parsedFile)
+}
+
 func MapCommentMode(mode CommentMode) engine.CommentMode {
 	switch mode {
 	case CommentsFollow:
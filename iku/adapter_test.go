@@ -0,0 +1,78 @@
+package iku
+
+import (
+	"github.com/Fuwn/iku/engine"
+	"testing"
+)
+
+type testAdapter struct{}
+
+func (a *testAdapter) Name() string {
+	return "test"
+}
+
+func (a *testAdapter) Extensions() []string {
+	return []string{".ikutest"}
+}
+
+func (a *testAdapter) LanguageIdentifiers() []string {
+	return []string{"ikutest"}
+}
+
+func (a *testAdapter) Interpreters() []string {
+	return []string{"ikutest"}
+}
+
+func (a *testAdapter) Analyze(source []byte) ([]byte, []engine.LineEvent, error) {
+	return source, []engine.LineEvent{{Content: "ok", TrimmedContent: "ok"}}, nil
+}
+
+func TestSelectAdapter(t *testing.T) {
+	testCases := []struct {
+		name               string
+		source             string
+		filename           string
+		languageIdentifier string
+		expectedAdapter    string
+	}{
+		{"go extension", "", "main.go", "", "go"},
+		{"tsx extension", "", "app.tsx", "", "ecmascript"},
+		{"language identifier wins", "", "main.go", "typescript", "ecmascript"},
+		{"unknown language identifier", "", "app.js", "plaintext", "ecmascript"},
+		{"shebang", "#!/usr/bin/node\n", "script", "", "ecmascript"},
+		{"env shebang", "#!/usr/bin/env -S deno run\n", "script", "", "ecmascript"},
+		{"fallback", "package main\n", "<stdin>", "", "go"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			selectedAdapter := SelectAdapter([]byte(testCase.source), testCase.filename, testCase.languageIdentifier)
+
+			if selectedAdapter.Name() != testCase.expectedAdapter {
+				t.Errorf("got %s, want %s", selectedAdapter.Name(), testCase.expectedAdapter)
+			}
+		})
+	}
+}
+
+func TestRegisterAdapter(t *testing.T) {
+	RegisterAdapter(&testAdapter{})
+
+	if !IsSupportedFile("notes.ikutest") {
+		t.Error("expected registered extension to be supported")
+	}
+
+	if IsSupportedFile("notes.txt") {
+		t.Error("expected unregistered extension to be unsupported")
+	}
+
+	formattedResult, err := Format([]byte("anything"), "notes.ikutest", Options{})
+
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if string(formattedResult) != "ok\n" {
+		t.Errorf("got %q, want %q", formattedResult, "ok\n")
+	}
+}
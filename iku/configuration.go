@@ -0,0 +1,418 @@
+package iku
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/Fuwn/iku/internal/pathpattern"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type Configuration struct {
+	GroupSingleLineFunctions bool                                    `json:"group_single_line_functions"`
+	CommentMode              string                                  `json:"comment_mode"`
+	PreserveBlankLines       bool                                    `json:"preserve_blank_lines"`
+	Adapters                 map[string]ExternalAdapterConfiguration `json:"adapters,omitempty"`
+	Overrides                map[string]Configuration                `json:"overrides,omitempty"`
+	Go                       *LanguageConfiguration                  `json:"go,omitempty"`
+	JavaScript               *LanguageConfiguration                  `json:"javascript,omitempty"`
+	TypeScript               *LanguageConfiguration                  `json:"typescript,omitempty"`
+	StatementGroups          map[string][]string                     `json:"statement_groups,omitempty"`
+	ScopedKinds              []string                                `json:"scoped_kinds,omitempty"`
+	BlankLines               *BlankLineConfiguration                 `json:"blank_lines,omitempty"`
+}
+
+type LanguageConfiguration struct {
+	GroupSingleLineFunctions *bool                   `json:"group_single_line_functions"`
+	CommentMode              *string                 `json:"comment_mode"`
+	PreserveBlankLines       *bool                   `json:"preserve_blank_lines"`
+	StatementGroups          map[string][]string     `json:"statement_groups"`
+	ScopedKinds              []string                `json:"scoped_kinds"`
+	BlankLines               *BlankLineConfiguration `json:"blank_lines"`
+}
+
+type BlankLineConfiguration struct {
+	TopLevel       *int `json:"top_level"`
+	ScopeBoundary  *int `json:"scope_boundary"`
+	TypeTransition *int `json:"type_transition"`
+}
+
+const maximumBlankLines = 3
+
+func (configuration Configuration) languageSection(language string) *LanguageConfiguration {
+	switch language {
+	case "go":
+		return configuration.Go
+	case "javascript":
+		return configuration.JavaScript
+	case "typescript":
+		return configuration.TypeScript
+	default:
+		return nil
+	}
+}
+
+func (configuration Configuration) withLanguageSection(languageSection LanguageConfiguration) Configuration {
+	if languageSection.GroupSingleLineFunctions != nil {
+		configuration.GroupSingleLineFunctions = *languageSection.GroupSingleLineFunctions
+	}
+
+	if languageSection.CommentMode != nil {
+		configuration.CommentMode = *languageSection.CommentMode
+	}
+
+	if languageSection.PreserveBlankLines != nil {
+		configuration.PreserveBlankLines = *languageSection.PreserveBlankLines
+	}
+
+	if languageSection.StatementGroups != nil {
+		statementGroups := make(map[string][]string)
+		sectionStatementTypes := make(map[string]bool)
+
+		for _, statementTypes := range languageSection.StatementGroups {
+			for _, statementType := range statementTypes {
+				sectionStatementTypes[statementType] = true
+			}
+		}
+
+		for groupName, statementTypes := range configuration.StatementGroups {
+			statementGroups[groupName] = slices.DeleteFunc(slices.Clone(statementTypes), func(statementType string) bool {
+				return sectionStatementTypes[statementType]
+			})
+		}
+
+		maps.Copy(statementGroups, languageSection.StatementGroups)
+
+		configuration.StatementGroups = statementGroups
+	}
+
+	if languageSection.ScopedKinds != nil {
+		configuration.ScopedKinds = languageSection.ScopedKinds
+	}
+
+	if languageSection.BlankLines != nil {
+		blankLines := BlankLineConfiguration{}
+
+		if configuration.BlankLines != nil {
+			blankLines = *configuration.BlankLines
+		}
+
+		blankLines.TopLevel = cmp.Or(languageSection.BlankLines.TopLevel, blankLines.TopLevel)
+		blankLines.ScopeBoundary = cmp.Or(languageSection.BlankLines.ScopeBoundary, blankLines.ScopeBoundary)
+		blankLines.TypeTransition = cmp.Or(languageSection.BlankLines.TypeTransition, blankLines.TypeTransition)
+		configuration.BlankLines = &blankLines
+	}
+
+	return configuration
+}
+
+func (configuration Configuration) statementGroups() map[string]string {
+	if len(configuration.StatementGroups) == 0 {
+		return nil
+	}
+
+	statementGroups := make(map[string]string)
+
+	for groupName, statementTypes := range configuration.StatementGroups {
+		for _, statementType := range statementTypes {
+			statementGroups[statementType] = groupName
+		}
+	}
+
+	return statementGroups
+}
+
+func statementGroupProblems(fieldPath string, statementGroups map[string][]string) []configurationProblem {
+	var problems []configurationProblem
+
+	groupedStatementTypes := make(map[string]string)
+
+	for _, groupName := range slices.Sorted(maps.Keys(statementGroups)) {
+		groupPath := joinConfigurationKeyPath(fieldPath, groupName)
+
+		if groupName == "" {
+			problems = append(problems, configurationProblem{field: groupPath, err: errors.New("statement_groups: group name must not be empty")})
+		}
+
+		for _, statementType := range statementGroups[groupName] {
+			switch previousGroupName, isGrouped := groupedStatementTypes[statementType]; {
+			case statementType == "":
+				problems = append(problems, configurationProblem{field: groupPath, err: fmt.Errorf("statement_groups: %s: statement type must not be empty", groupName)})
+			case isGrouped:
+				problems = append(problems, configurationProblem{field: groupPath, err: fmt.Errorf("statement_groups: %s: %q is already in group %s", groupName, statementType, previousGroupName)})
+			default:
+				groupedStatementTypes[statementType] = groupName
+			}
+		}
+	}
+
+	return problems
+}
+
+func (configuration Configuration) scopedKinds() map[string]bool {
+	if configuration.ScopedKinds == nil {
+		return nil
+	}
+
+	scopedKinds := make(map[string]bool, len(configuration.ScopedKinds))
+
+	for _, scopeKind := range configuration.ScopedKinds {
+		scopedKinds[scopeKind] = true
+	}
+
+	return scopedKinds
+}
+
+func knownScopeKinds(language string) []string {
+	switch language {
+	case "go":
+		return goScopeKinds
+	case "javascript", "typescript":
+		return ecmaScriptScopeKinds
+	default:
+		return slices.Compact(slices.Sorted(slices.Values(slices.Concat(goScopeKinds, ecmaScriptScopeKinds))))
+	}
+}
+
+func defaultScopedKinds(language string) []string {
+	switch language {
+	case "go":
+		return defaultGoScopedKinds
+	case "javascript", "typescript":
+		return ecmaScriptScopeKinds
+	default:
+		return nil
+	}
+}
+
+func scopedKindProblems(fieldPath string, language string, scopedKinds []string) []configurationProblem {
+	var problems []configurationProblem
+
+	knownKinds := knownScopeKinds(language)
+
+	for _, scopeKind := range scopedKinds {
+		if slices.Contains(knownKinds, scopeKind) {
+			continue
+		}
+
+		err := fmt.Errorf("scoped_kinds: unknown kind %q", scopeKind)
+
+		if closestKind := closestConfigurationFieldName(scopeKind, knownKinds); closestKind != "" {
+			err = fmt.Errorf("scoped_kinds: unknown kind %q (did you mean %q?)", scopeKind, closestKind)
+		}
+
+		if language != "" {
+			err = fmt.Errorf("%s: %v", language, err)
+		}
+
+		problems = append(problems, configurationProblem{field: fieldPath, err: err})
+	}
+
+	return problems
+}
+
+func (configuration Configuration) blankLineCounts() (int, int, int) {
+	var blankLines BlankLineConfiguration
+
+	if configuration.BlankLines != nil {
+		blankLines = *configuration.BlankLines
+	}
+
+	return blankLineCount(blankLines.TopLevel), blankLineCount(blankLines.ScopeBoundary), blankLineCount(blankLines.TypeTransition)
+}
+
+func blankLineCount(configuredCount *int) int {
+	if configuredCount == nil {
+		return 1
+	}
+
+	return *configuredCount
+}
+
+func blankLineProblems(fieldPath string, language string, blankLines *BlankLineConfiguration) []configurationProblem {
+	var problems []configurationProblem
+
+	if blankLines == nil {
+		return nil
+	}
+
+	for _, blankLineField := range []struct {
+		name  string
+		count *int
+	}{
+		{"top_level", blankLines.TopLevel},
+		{"scope_boundary", blankLines.ScopeBoundary},
+		{"type_transition", blankLines.TypeTransition},
+	} {
+		if blankLineField.count == nil || (*blankLineField.count >= 1 && *blankLineField.count <= maximumBlankLines) {
+			continue
+		}
+
+		err := fmt.Errorf("blank_lines: %s must be between 1 and %d, got %d", blankLineField.name, maximumBlankLines, *blankLineField.count)
+
+		if language != "" {
+			err = fmt.Errorf("%s: %v", language, err)
+		}
+
+		problems = append(problems, configurationProblem{field: joinConfigurationFieldPath(fieldPath, blankLineField.name), err: err})
+	}
+
+	return problems
+}
+
+func (configuration Configuration) commentMode() (CommentMode, error) {
+	switch strings.ToLower(configuration.CommentMode) {
+	case "", "follow":
+		return CommentsFollow, nil
+	case "precede":
+		return CommentsPrecede, nil
+	case "standalone":
+		return CommentsStandalone, nil
+	default:
+		return 0, fmt.Errorf("invalid comment_mode: %q (use follow, precede, or standalone)", configuration.CommentMode)
+	}
+}
+
+func (configuration Configuration) problems() []configurationProblem {
+	var problems []configurationProblem
+
+	if _, err := configuration.commentMode(); err != nil {
+		problems = append(problems, configurationProblem{field: "comment_mode", err: err})
+	}
+
+	for _, extension := range slices.Sorted(maps.Keys(configuration.Adapters)) {
+		if _, err := newExternalAdapter(extension, configuration.Adapters[extension]); err != nil {
+			problems = append(problems, configurationProblem{field: joinConfigurationKeyPath("adapters", extension), err: err})
+		}
+	}
+
+	problems = append(problems, statementGroupProblems("statement_groups", configuration.StatementGroups)...)
+	problems = append(problems, scopedKindProblems("scoped_kinds", "", configuration.ScopedKinds)...)
+	problems = append(problems, blankLineProblems("blank_lines", "", configuration.BlankLines)...)
+
+	for _, language := range []string{"go", "javascript", "typescript"} {
+		languageSection := configuration.languageSection(language)
+
+		if languageSection == nil {
+			continue
+		}
+
+		if languageSection.CommentMode != nil {
+			if _, err := (Configuration{CommentMode: *languageSection.CommentMode}).commentMode(); err != nil {
+				problems = append(problems, configurationProblem{field: joinConfigurationFieldPath(language, "comment_mode"), err: fmt.Errorf("%s: %v", language, err)})
+			}
+		}
+
+		if languageSection.StatementGroups != nil {
+			problems = append(problems, statementGroupProblems(joinConfigurationFieldPath(language, "statement_groups"), languageSection.StatementGroups)...)
+		}
+
+		problems = append(problems, scopedKindProblems(joinConfigurationFieldPath(language, "scoped_kinds"), language, languageSection.ScopedKinds)...)
+		problems = append(problems, blankLineProblems(joinConfigurationFieldPath(language, "blank_lines"), language, languageSection.BlankLines)...)
+	}
+
+	for _, overridePattern := range slices.Sorted(maps.Keys(configuration.Overrides)) {
+		overridePath := joinConfigurationKeyPath("overrides", overridePattern)
+		overrideConfiguration := configuration.Overrides[overridePattern]
+
+		if _, err := pathpattern.Compile(overridePattern); err != nil || overridePattern == "" {
+			problems = append(problems, configurationProblem{field: overridePath, err: fmt.Errorf("overrides: invalid pattern %q", overridePattern)})
+		}
+
+		if len(overrideConfiguration.Overrides) > 0 {
+			problems = append(problems, configurationProblem{field: joinConfigurationFieldPath(overridePath, "overrides"), err: fmt.Errorf("overrides: %s: overrides cannot be nested", overridePattern)})
+		}
+
+		for _, overrideProblem := range overrideConfiguration.problems() {
+			problems = append(problems, configurationProblem{field: joinConfigurationFieldPath(overridePath, overrideProblem.field), err: overrideProblem.err})
+		}
+	}
+
+	return problems
+}
+
+func (configuration Configuration) validate() error {
+	if problems := configuration.problems(); len(problems) > 0 {
+		return problems[0].err
+	}
+
+	return nil
+}
+
+func (configuration Configuration) externalAdapterFor(filename string) *ExternalAdapter {
+	fileExtension := filepath.Ext(filename)
+	adapterConfiguration, isConfigured := configuration.Adapters[fileExtension]
+
+	if !isConfigured {
+		return nil
+	}
+
+	externalAdapter, err := newExternalAdapter(fileExtension, adapterConfiguration)
+
+	if err != nil {
+		return nil
+	}
+
+	return externalAdapter
+}
+
+func (configuration Configuration) IsSupportedFile(filename string) bool {
+	return configuration.externalAdapterFor(filename) != nil || IsSupportedFile(filename)
+}
+
+var configurationFileNames = []string{".iku.json", "iku.json"}
+
+func FindConfigurationFile(directoryPath string) string {
+	for _, fileName := range configurationFileNames {
+		configurationPath := filepath.Join(directoryPath, fileName)
+
+		if fileInformation, err := os.Stat(configurationPath); err == nil && !fileInformation.IsDir() {
+			return configurationPath
+		}
+	}
+
+	return ""
+}
+
+func readConfigurationFile(configurationPath string) ([]byte, error) {
+	fileData, err := os.ReadFile(configurationPath)
+
+	if err != nil {
+		var pathError *fs.PathError
+
+		if errors.As(err, &pathError) {
+			err = pathError.Err
+		}
+
+		return nil, &ConfigurationError{Path: configurationPath, Err: err}
+	}
+
+	return fileData, nil
+}
+
+func LoadConfigurationFile(configurationPath string) (Configuration, error) {
+	fileData, err := readConfigurationFile(configurationPath)
+
+	if err != nil {
+		return Configuration{}, err
+	}
+
+	configuration, problems := decodeConfiguration(configurationPath, fileData)
+
+	return configuration, errors.Join(problems...)
+}
+
+func LoadConfiguration(directoryPath string) (Configuration, error) {
+	configurationPath := FindConfigurationFile(directoryPath)
+
+	if configurationPath == "" {
+		return Configuration{}, nil
+	}
+
+	return LoadConfigurationFile(configurationPath)
+}
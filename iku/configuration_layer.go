@@ -0,0 +1,306 @@
+package iku
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Fuwn/iku/internal/pathpattern"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type configurationOverrideLayer struct {
+	pattern    string
+	expression *regexp.Regexp
+	values     map[string]any
+}
+
+type ConfigurationLayer struct {
+	Path           string
+	Directory      string
+	AllowsAdapters bool
+	values         map[string]any
+	overrides      []configurationOverrideLayer
+}
+
+func LoadConfigurationLayer(configurationPath string) (*ConfigurationLayer, error) {
+	fileData, err := readConfigurationFile(configurationPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, problems := decodeConfiguration(configurationPath, fileData); len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+
+	absoluteConfigurationPath, err := filepath.Abs(configurationPath)
+
+	if err != nil {
+		return nil, &ConfigurationError{Path: configurationPath, Err: err}
+	}
+
+	var rawValues map[string]json.RawMessage
+
+	layer := &ConfigurationLayer{Path: configurationPath, Directory: filepath.Dir(absoluteConfigurationPath), values: make(map[string]any)}
+
+	if err := json.Unmarshal(fileData, &rawValues); err != nil {
+		return nil, &ConfigurationError{Path: configurationPath, Err: err}
+	}
+
+	for fieldName, rawValue := range rawValues {
+		if fieldName == "overrides" {
+			if layer.overrides, err = decodeConfigurationOverrides(rawValue); err != nil {
+				return nil, &ConfigurationError{Path: configurationPath, Err: err}
+			}
+
+			continue
+		}
+
+		var fieldValue any
+
+		if err := json.Unmarshal(rawValue, &fieldValue); err != nil {
+			return nil, &ConfigurationError{Path: configurationPath, Err: err}
+		}
+
+		layer.values[fieldName] = fieldValue
+	}
+
+	return layer, nil
+}
+
+func decodeConfigurationOverrides(rawOverrides json.RawMessage) ([]configurationOverrideLayer, error) {
+	var overrides []configurationOverrideLayer
+
+	decoder := json.NewDecoder(bytes.NewReader(rawOverrides))
+
+	if token, err := decoder.Token(); err != nil || token == nil {
+		return nil, err
+	}
+
+	for decoder.More() {
+		patternToken, err := decoder.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		override := configurationOverrideLayer{pattern: patternToken.(string)}
+
+		if err := decoder.Decode(&override.values); err != nil {
+			return nil, err
+		}
+
+		if override.expression, err = pathpattern.Compile(override.pattern); err != nil {
+			return nil, fmt.Errorf("overrides: invalid pattern %q", override.pattern)
+		}
+
+		overrides = append(overrides, override)
+	}
+
+	return overrides, nil
+}
+
+type ConfigurationExplanation struct {
+	Language      string            `json:"language,omitempty"`
+	Files         []string          `json:"files"`
+	Configuration Configuration     `json:"configuration"`
+	Sources       map[string]string `json:"sources"`
+}
+
+func MergeConfigurationLayers(layers []*ConfigurationLayer, filePath string) (Configuration, error) {
+	configuration, err := mergeConfigurationLayers(layers, filePath, nil)
+
+	return configuration, err
+}
+
+func ExplainConfiguration(layers []*ConfigurationLayer, filePath string, source []byte) (*ConfigurationExplanation, error) {
+	valueSources := make(map[string]string)
+	configuration, err := mergeConfigurationLayers(layers, filePath, valueSources)
+
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &ConfigurationExplanation{Files: []string{}, Sources: make(map[string]string)}
+	explanation.Language = configurationLanguage((&Formatter{Configuration: configuration}).adapter(source, filePath), filePath, "")
+
+	for _, layer := range layers {
+		explanation.Files = append(explanation.Files, layer.Path)
+	}
+
+	for valuePath, valueSource := range valueSources {
+		if fieldName, _, _ := strings.Cut(valuePath, "."); fieldName != "go" && fieldName != "javascript" && fieldName != "typescript" {
+			explanation.Sources[valuePath] = valueSource
+		}
+	}
+
+	if languageSection := configuration.languageSection(explanation.Language); languageSection != nil {
+		configuration = configuration.withLanguageSection(*languageSection)
+
+		for valuePath, valueSource := range valueSources {
+			if languageValuePath, isLanguageValue := strings.CutPrefix(valuePath, explanation.Language+"."); isLanguageValue {
+				explanation.Sources[languageValuePath] = valueSource
+			}
+		}
+	}
+
+	for _, fieldName := range []string{"comment_mode", "group_single_line_functions", "preserve_blank_lines"} {
+		if _, isSet := explanation.Sources[fieldName]; !isSet {
+			explanation.Sources[fieldName] = "default"
+		}
+	}
+
+	if configuration.ScopedKinds == nil && defaultScopedKinds(explanation.Language) != nil {
+		configuration.ScopedKinds = defaultScopedKinds(explanation.Language)
+		explanation.Sources["scoped_kinds"] = "default"
+	}
+
+	topLevelBlankLines, scopeBoundaryBlankLines, typeTransitionBlankLines := configuration.blankLineCounts()
+	configuration.BlankLines = &BlankLineConfiguration{TopLevel: &topLevelBlankLines, ScopeBoundary: &scopeBoundaryBlankLines, TypeTransition: &typeTransitionBlankLines}
+
+	for _, fieldName := range []string{"top_level", "scope_boundary", "type_transition"} {
+		if _, isSet := explanation.Sources[joinConfigurationFieldPath("blank_lines", fieldName)]; !isSet {
+			explanation.Sources[joinConfigurationFieldPath("blank_lines", fieldName)] = "default"
+		}
+	}
+
+	for extension, adapterConfiguration := range configuration.Adapters {
+		if adapterConfiguration.Timeout == "" {
+			adapterConfiguration.Timeout = defaultExternalAdapterTimeout.String()
+			configuration.Adapters[extension] = adapterConfiguration
+			explanation.Sources[joinConfigurationFieldPath(joinConfigurationKeyPath("adapters", extension), "timeout")] = "default"
+		}
+	}
+
+	configuration.CommentMode = strings.ToLower(cmp.Or(configuration.CommentMode, "follow"))
+	configuration.Go, configuration.JavaScript, configuration.TypeScript = nil, nil, nil
+	explanation.Configuration = configuration
+
+	return explanation, nil
+}
+
+func mergeConfigurationLayers(layers []*ConfigurationLayer, filePath string, valueSources map[string]string) (Configuration, error) {
+	var configuration Configuration
+
+	mergedValues := make(map[string]any)
+	absoluteFilePath, err := filepath.Abs(filePath)
+
+	if err != nil {
+		return configuration, err
+	}
+
+	for _, layer := range layers {
+		if !layer.AllowsAdapters && layer.definesAdapters() {
+			return configuration, &ConfigurationError{Path: layer.Path, Err: errors.New("adapters: only allowed in the configuration file at the repository root or working directory, or the one given with -config")}
+		}
+
+		mergeConfigurationValues(mergedValues, layer.values, "", layer.Path, valueSources)
+
+		relativePath, err := filepath.Rel(layer.Directory, absoluteFilePath)
+
+		if err != nil {
+			continue
+		}
+
+		for _, override := range layer.overrides {
+			if override.expression.MatchString(filepath.ToSlash(relativePath)) {
+				mergeConfigurationValues(mergedValues, override.values, "", fmt.Sprintf("%s (%s)", layer.Path, joinConfigurationKeyPath("overrides", override.pattern)), valueSources)
+			}
+		}
+	}
+
+	mergedContent, err := json.Marshal(mergedValues)
+
+	if err != nil {
+		return configuration, err
+	}
+
+	if err := json.Unmarshal(mergedContent, &configuration); err != nil {
+		return configuration, &ConfigurationError{Err: err}
+	}
+
+	if err := configuration.validate(); err != nil {
+		return configuration, &ConfigurationError{Err: err}
+	}
+
+	return configuration, nil
+}
+
+func (l *ConfigurationLayer) definesAdapters() bool {
+	if _, isDefined := l.values["adapters"]; isDefined {
+		return true
+	}
+
+	for _, override := range l.overrides {
+		if _, isDefined := override.values["adapters"]; isDefined {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mergeConfigurationValues(targetValues map[string]any, sourceValues map[string]any, parentPath string, sourceLabel string, valueSources map[string]string) {
+	for fieldName, sourceValue := range sourceValues {
+		valuePath := joinConfigurationFieldPath(parentPath, fieldName)
+		sourceObject, isSourceObject := sourceValue.(map[string]any)
+		targetObject, isTargetObject := targetValues[fieldName].(map[string]any)
+
+		if isConfigurationMapPath(parentPath) {
+			valuePath = joinConfigurationKeyPath(parentPath, fieldName)
+		}
+
+		if isSourceObject && isTargetObject {
+			mergedObject := maps.Clone(targetObject)
+
+			mergeConfigurationValues(mergedObject, sourceObject, valuePath, sourceLabel, valueSources)
+
+			targetValues[fieldName] = mergedObject
+
+			continue
+		}
+
+		targetValues[fieldName] = sourceValue
+
+		if valueSources != nil {
+			for existingPath := range valueSources {
+				if strings.HasPrefix(existingPath, valuePath+".") || strings.HasPrefix(existingPath, valuePath+"[") {
+					delete(valueSources, existingPath)
+				}
+			}
+
+			recordConfigurationSources(valuePath, sourceValue, sourceLabel, valueSources)
+		}
+	}
+}
+
+func isConfigurationMapPath(valuePath string) bool {
+	_, fieldName, _ := strings.Cut(valuePath, ".")
+
+	return valuePath == "adapters" || valuePath == "statement_groups" || fieldName == "statement_groups"
+}
+
+func recordConfigurationSources(valuePath string, value any, sourceLabel string, valueSources map[string]string) {
+	objectValue, isObject := value.(map[string]any)
+
+	if !isObject {
+		valueSources[valuePath] = sourceLabel
+
+		return
+	}
+
+	for fieldName, fieldValue := range objectValue {
+		childPath := joinConfigurationFieldPath(valuePath, fieldName)
+
+		if isConfigurationMapPath(valuePath) {
+			childPath = joinConfigurationKeyPath(valuePath, fieldName)
+		}
+
+		recordConfigurationSources(childPath, fieldValue, sourceLabel, valueSources)
+	}
+}
@@ -0,0 +1,201 @@
+package iku
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfigurationLayer(t *testing.T, directoryPath string, content string) *ConfigurationLayer {
+	t.Helper()
+
+	configurationPath := filepath.Join(directoryPath, ".iku.json")
+
+	if err := os.MkdirAll(directoryPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(configurationPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	layer, err := LoadConfigurationLayer(configurationPath)
+
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+
+	layer.AllowsAdapters = true
+
+	return layer
+}
+
+func TestMergeConfigurationLayers(t *testing.T) {
+	rootDirectory := t.TempDir()
+	rootLayer := writeConfigurationLayer(t, rootDirectory, `{
+  "comment_mode": "follow",
+  "group_single_line_functions": true,
+  "adapters": {".rules": {"command": ["rules"], "timeout": "1s"}},
+  "overrides": {
+    "**/*_test.go": {"group_single_line_functions": false, "comment_mode": "precede"},
+    "*_test.go": {"comment_mode": "standalone"}
+  }
+}`)
+	webLayer := writeConfigurationLayer(t, filepath.Join(rootDirectory, "web"), `{
+  "comment_mode": "precede",
+  "adapters": {".rules": {"command": ["web-rules"]}},
+  "overrides": {"legacy/**": {"comment_mode": "standalone"}}
+}`)
+	testCases := []struct {
+		name                             string
+		layers                           []*ConfigurationLayer
+		filePath                         string
+		expectedCommentMode              string
+		expectedGroupSingleLineFunctions bool
+		expectedAdapterCommand           string
+		expectedAdapterTimeout           string
+	}{
+		{"root file", []*ConfigurationLayer{rootLayer}, "service/main.go", "follow", true, "rules", "1s"},
+		{"overrides in declared order", []*ConfigurationLayer{rootLayer}, "service/main_test.go", "standalone", false, "rules", "1s"},
+		{"child inherits and overrides", []*ConfigurationLayer{rootLayer, webLayer}, "web/app.ts", "precede", true, "web-rules", "1s"},
+		{"child override relative to child", []*ConfigurationLayer{rootLayer, webLayer}, "web/legacy/old.ts", "standalone", true, "web-rules", "1s"},
+		{"child base beats parent override", []*ConfigurationLayer{rootLayer, webLayer}, "web/app_test.go", "precede", false, "web-rules", "1s"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			configuration, err := MergeConfigurationLayers(testCase.layers, filepath.Join(rootDirectory, filepath.FromSlash(testCase.filePath)))
+
+			if err != nil {
+				t.Fatalf("merge error: %v", err)
+			}
+
+			if configuration.CommentMode != testCase.expectedCommentMode {
+				t.Errorf("comment_mode: got %q, want %q", configuration.CommentMode, testCase.expectedCommentMode)
+			}
+
+			if configuration.GroupSingleLineFunctions != testCase.expectedGroupSingleLineFunctions {
+				t.Errorf("group_single_line_functions: got %v, want %v", configuration.GroupSingleLineFunctions, testCase.expectedGroupSingleLineFunctions)
+			}
+
+			if adapter := configuration.Adapters[".rules"]; adapter.Command[0] != testCase.expectedAdapterCommand || adapter.Timeout != testCase.expectedAdapterTimeout {
+				t.Errorf("adapters: got %v, want command %s and timeout %s", adapter, testCase.expectedAdapterCommand, testCase.expectedAdapterTimeout)
+			}
+		})
+	}
+}
+
+func TestLoadConfigurationLayerRejectsNestedOverrides(t *testing.T) {
+	configurationPath := filepath.Join(t.TempDir(), ".iku.json")
+	configurationContent := `{"overrides": {"*.go": {"overrides": {"*.go": {}}}}}`
+
+	if err := os.WriteFile(configurationPath, []byte(configurationContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfigurationLayer(configurationPath); err == nil {
+		t.Error("expected nested overrides to be rejected")
+	}
+}
+
+func TestMergeConfigurationLayersRejectsUntrustedAdapters(t *testing.T) {
+	rootDirectory := t.TempDir()
+	rootLayer := writeConfigurationLayer(t, rootDirectory, `{"comment_mode": "follow"}`)
+
+	for _, content := range []string{
+		`{"adapters": {".rules": {"command": ["rules"]}}}`,
+		`{"overrides": {"*.rules": {"adapters": {".rules": {"command": ["rules"]}}}}}`,
+	} {
+		nestedLayer := writeConfigurationLayer(t, filepath.Join(rootDirectory, "nested"), content)
+		nestedLayer.AllowsAdapters = false
+
+		if _, err := MergeConfigurationLayers([]*ConfigurationLayer{rootLayer, nestedLayer}, filepath.Join(rootDirectory, "nested", "a.rules")); err == nil {
+			t.Errorf("expected adapters in %s to be rejected", content)
+		}
+	}
+}
+
+func TestExplainConfiguration(t *testing.T) {
+	rootDirectory := t.TempDir()
+	rootLayer := writeConfigurationLayer(t, rootDirectory, `{
+  "comment_mode": "follow",
+  "blank_lines": {"top_level": 2},
+  "adapters": {".rules": {"command": ["rules"]}},
+  "overrides": {"**/*_test.go": {"group_single_line_functions": true}}
+}`)
+	webLayer := writeConfigurationLayer(t, filepath.Join(rootDirectory, "web"), `{
+  "typescript": {"comment_mode": "precede", "scoped_kinds": ["function", "class"], "blank_lines": {"type_transition": 2}}
+}`)
+	testCases := []struct {
+		name                string
+		layers              []*ConfigurationLayer
+		filePath            string
+		expectedLanguage    string
+		expectedCommentMode string
+		expectedSources     map[string]string
+	}{
+		{
+			"override and defaults",
+			[]*ConfigurationLayer{rootLayer},
+			"service/main_test.go",
+			"go",
+			"follow",
+			map[string]string{
+				"comment_mode":                rootLayer.Path,
+				"group_single_line_functions": rootLayer.Path + ` (overrides["**/*_test.go"])`,
+				"preserve_blank_lines":        "default",
+				"scoped_kinds":                "default",
+				"blank_lines.top_level":       rootLayer.Path,
+				"blank_lines.scope_boundary":  "default",
+				"blank_lines.type_transition": "default",
+				`adapters[".rules"].command`:  rootLayer.Path,
+				`adapters[".rules"].timeout`:  "default",
+			},
+		},
+		{
+			"language section",
+			[]*ConfigurationLayer{rootLayer, webLayer},
+			"web/app.ts",
+			"typescript",
+			"precede",
+			map[string]string{
+				"comment_mode":                webLayer.Path,
+				"group_single_line_functions": "default",
+				"preserve_blank_lines":        "default",
+				"scoped_kinds":                webLayer.Path,
+				"blank_lines.top_level":       rootLayer.Path,
+				"blank_lines.scope_boundary":  "default",
+				"blank_lines.type_transition": webLayer.Path,
+				`adapters[".rules"].command`:  rootLayer.Path,
+				`adapters[".rules"].timeout`:  "default",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			explanation, err := ExplainConfiguration(testCase.layers, filepath.Join(rootDirectory, filepath.FromSlash(testCase.filePath)), nil)
+
+			if err != nil {
+				t.Fatalf("explain error: %v", err)
+			}
+
+			if explanation.Language != testCase.expectedLanguage {
+				t.Errorf("language: got %q, want %q", explanation.Language, testCase.expectedLanguage)
+			}
+
+			if explanation.Configuration.CommentMode != testCase.expectedCommentMode {
+				t.Errorf("comment_mode: got %q, want %q", explanation.Configuration.CommentMode, testCase.expectedCommentMode)
+			}
+
+			if explanation.Configuration.TypeScript != nil {
+				t.Error("expected language sections to be applied and removed")
+			}
+
+			if !reflect.DeepEqual(explanation.Sources, testCase.expectedSources) {
+				t.Errorf("sources: got %v, want %v", explanation.Sources, testCase.expectedSources)
+			}
+		})
+	}
+}
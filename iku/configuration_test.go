@@ -0,0 +1,108 @@
+package iku
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigurationFileValidation(t *testing.T) {
+	testCases := []struct {
+		name           string
+		content        string
+		expectedErrors []string
+	}{
+		{"valid", `{"comment_mode": "precede", "group_single_line_functions": true}`, nil},
+		{"syntax error", "{\n  \"comment_mode\": \"follow\",\n}", []string{"iku.json:3:1: invalid character '}' looking for beginning of object key string"}},
+		{
+			"misspelled field",
+			"{\n  \"comment-mode\": \"precede\"\n}",
+			[]string{`iku.json:2:3: unknown field "comment-mode" (did you mean "comment_mode"?)`},
+		},
+		{
+			"unknown nested field",
+			`{"adapters": {".rules": {"command": ["rules"], "timout": "1s"}}}`,
+			[]string{`iku.json:1:48: unknown field "timout" in adapters[".rules"] (did you mean "timeout"?)`},
+		},
+		{"unrelated field", `{"indent": 4}`, []string{`iku.json:1:2: unknown field "indent"`}},
+		{
+			"statement type in two groups",
+			"{\n  \"statement_groups\": {\"bindings\": [\"var\", \"const\"], \"declarations\": [\"const\"]}\n}",
+			[]string{`iku.json:2:54: statement_groups: declarations: "const" is already in group bindings`},
+		},
+		{
+			"unknown scoped kind",
+			"{\n  \"scoped_kinds\": [\"if\", \"selct\"],\n  \"go\": {\"scoped_kinds\": [\"while\"]}\n}",
+			[]string{
+				`iku.json:2:3: scoped_kinds: unknown kind "selct" (did you mean "select"?)`,
+				`iku.json:3:10: go: scoped_kinds: unknown kind "while"`,
+			},
+		},
+		{
+			"blank line count out of range",
+			"{\n  \"blank_lines\": {\"top_level\": 0},\n  \"javascript\": {\"blank_lines\": {\"type_transition\": 4}}\n}",
+			[]string{
+				"iku.json:2:19: blank_lines: top_level must be between 1 and 3, got 0",
+				"iku.json:3:34: javascript: blank_lines: type_transition must be between 1 and 3, got 4",
+			},
+		},
+		{
+			"wrong type and invalid value",
+			"{\n  \"group_single_line_functions\": \"yes\",\n  \"comment_mode\": \"sideways\"\n}",
+			[]string{
+				"iku.json:2:3: group_single_line_functions: expected boolean, got string",
+				`iku.json:3:3: invalid comment_mode: "sideways" (use follow, precede, or standalone)`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var errorMessages []string
+
+			configurationPath := filepath.Join(t.TempDir(), "iku.json")
+
+			if err := os.WriteFile(configurationPath, []byte(testCase.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadConfigurationFile(configurationPath)
+
+			if err != nil {
+				for _, wrappedError := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var configurationError *ConfigurationError
+
+					if !errors.As(wrappedError, &configurationError) {
+						t.Fatalf("expected *ConfigurationError, got %T", wrappedError)
+					}
+
+					configurationError.Path = filepath.Base(configurationError.Path)
+					errorMessages = append(errorMessages, configurationError.Error())
+				}
+			}
+
+			if !reflect.DeepEqual(errorMessages, testCase.expectedErrors) {
+				t.Errorf("got %q, want %q", errorMessages, testCase.expectedErrors)
+			}
+		})
+	}
+}
+
+func TestClosestConfigurationFieldName(t *testing.T) {
+	knownFieldNames := []string{"comment_mode", "group_single_line_functions", "adapters"}
+	testCases := map[string]string{
+		"comment-mode":               "comment_mode",
+		"CommentMode":                "comment_mode",
+		"group_single_line_function": "group_single_line_functions",
+		"adaptors":                   "adapters",
+		"indent":                     "",
+	}
+
+	for fieldName, expectedSuggestion := range testCases {
+		if suggestion := closestConfigurationFieldName(fieldName, knownFieldNames); suggestion != expectedSuggestion {
+			t.Errorf("%s: got %q, want %q", fieldName, suggestion, expectedSuggestion)
+		}
+	}
+}
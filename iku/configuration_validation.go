@@ -0,0 +1,305 @@
+package iku
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+type configurationProblem struct {
+	field string
+	err   error
+}
+
+type configurationDecoder struct {
+	configurationPath string
+	fileData          []byte
+	decoder           *json.Decoder
+	fieldOffsets      map[string]int64
+	problems          []error
+}
+
+func decodeConfiguration(configurationPath string, fileData []byte) (Configuration, []error) {
+	var configuration Configuration
+	var typeError *json.UnmarshalTypeError
+
+	if err := json.Unmarshal(fileData, &configuration); err != nil {
+		var syntaxError *json.SyntaxError
+
+		switch {
+		case errors.As(err, &syntaxError):
+			return configuration, []error{newConfigurationError(configurationPath, fileData, max(syntaxError.Offset-1, 0), errors.New(strings.TrimPrefix(syntaxError.Error(), "json: ")))}
+		case errors.As(err, &typeError):
+		default:
+			return configuration, []error{&ConfigurationError{Path: configurationPath, Err: err}}
+		}
+	}
+
+	configurationDecoder := &configurationDecoder{
+		configurationPath: configurationPath,
+		fileData:          fileData,
+		decoder:           json.NewDecoder(bytes.NewReader(fileData)),
+		fieldOffsets:      make(map[string]int64),
+	}
+
+	if err := configurationDecoder.walk(reflect.TypeOf(configuration), ""); err != nil && err != io.EOF {
+		configurationDecoder.problems = append(configurationDecoder.problems, &ConfigurationError{Path: configurationPath, Err: err})
+	}
+
+	if typeError != nil {
+		fieldOffset, hasFieldOffset := configurationDecoder.fieldOffsets[typeError.Field]
+
+		if !hasFieldOffset {
+			fieldOffset = typeError.Offset
+		}
+
+		configurationDecoder.problems = append(configurationDecoder.problems, newConfigurationError(configurationPath, fileData, fieldOffset, fmt.Errorf("%s: expected %s, got %s", cmp.Or(typeError.Field[strings.LastIndex(typeError.Field, ".")+1:], "configuration"), describeConfigurationType(typeError.Type), typeError.Value)))
+	}
+
+	for _, problem := range configuration.problems() {
+		fieldOffset, hasFieldOffset := configurationDecoder.fieldOffsets[problem.field]
+
+		if !hasFieldOffset {
+			fieldOffset = -1
+		}
+
+		configurationDecoder.problems = append(configurationDecoder.problems, newConfigurationError(configurationPath, fileData, fieldOffset, problem.err))
+	}
+
+	slices.SortStableFunc(configurationDecoder.problems, func(firstProblem error, secondProblem error) int {
+		firstError, secondError := firstProblem.(*ConfigurationError), secondProblem.(*ConfigurationError)
+
+		if firstError.Line != secondError.Line {
+			return firstError.Line - secondError.Line
+		}
+
+		return firstError.Column - secondError.Column
+	})
+
+	return configuration, configurationDecoder.problems
+}
+
+func (d *configurationDecoder) walk(valueType reflect.Type, fieldPath string) error {
+	for valueType != nil && valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+
+	token, err := d.decoder.Token()
+
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		for d.decoder.More() {
+			keyToken, err := d.decoder.Token()
+
+			if err != nil {
+				return err
+			}
+
+			fieldName, _ := keyToken.(string)
+			keyOffset := int64(bytes.LastIndexByte(d.fileData[:d.decoder.InputOffset()-1], '"'))
+			childPath := joinConfigurationFieldPath(fieldPath, fieldName)
+
+			var childType reflect.Type
+
+			if valueType != nil && valueType.Kind() == reflect.Map {
+				childPath = joinConfigurationKeyPath(fieldPath, fieldName)
+			}
+
+			d.fieldOffsets[childPath] = keyOffset
+
+			switch {
+			case valueType == nil:
+			case valueType.Kind() == reflect.Map:
+				childType = valueType.Elem()
+			case valueType.Kind() == reflect.Struct:
+				knownFieldNames := configurationFieldNames(valueType)
+
+				if fieldIndex := slices.Index(knownFieldNames, fieldName); fieldIndex >= 0 {
+					childType = valueType.Field(configurationFieldIndices(valueType)[fieldIndex]).Type
+				} else {
+					d.problems = append(d.problems, newConfigurationError(d.configurationPath, d.fileData, keyOffset, unknownConfigurationFieldError(fieldPath, fieldName, knownFieldNames)))
+				}
+			}
+
+			if err := d.walk(childType, childPath); err != nil {
+				return err
+			}
+		}
+
+		_, err = d.decoder.Token()
+
+		return err
+	case json.Delim('['):
+		var elementType reflect.Type
+
+		if valueType != nil && (valueType.Kind() == reflect.Slice || valueType.Kind() == reflect.Array) {
+			elementType = valueType.Elem()
+		}
+
+		for elementIndex := 0; d.decoder.More(); elementIndex++ {
+			if err := d.walk(elementType, fmt.Sprintf("%s[%d]", fieldPath, elementIndex)); err != nil {
+				return err
+			}
+		}
+
+		_, err = d.decoder.Token()
+
+		return err
+	default:
+		return nil
+	}
+}
+
+func joinConfigurationFieldPath(parentPath string, fieldName string) string {
+	if parentPath == "" {
+		return fieldName
+	}
+
+	return parentPath + "." + fieldName
+}
+
+func joinConfigurationKeyPath(parentPath string, key string) string {
+	return fmt.Sprintf("%s[%q]", parentPath, key)
+}
+
+func configurationFieldNames(structType reflect.Type) []string {
+	var fieldNames []string
+
+	for fieldIndex := range structType.NumField() {
+		if fieldName := configurationFieldName(structType.Field(fieldIndex)); fieldName != "" {
+			fieldNames = append(fieldNames, fieldName)
+		}
+	}
+
+	return fieldNames
+}
+
+func configurationFieldIndices(structType reflect.Type) []int {
+	var fieldIndices []int
+
+	for fieldIndex := range structType.NumField() {
+		if configurationFieldName(structType.Field(fieldIndex)) != "" {
+			fieldIndices = append(fieldIndices, fieldIndex)
+		}
+	}
+
+	return fieldIndices
+}
+
+func configurationFieldName(structField reflect.StructField) string {
+	if !structField.IsExported() {
+		return ""
+	}
+
+	tagName, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+
+	switch tagName {
+	case "-":
+		return ""
+	case "":
+		return structField.Name
+	default:
+		return tagName
+	}
+}
+
+func unknownConfigurationFieldError(parentPath string, fieldName string, knownFieldNames []string) error {
+	unknownFieldMessage := fmt.Sprintf("unknown field %q", fieldName)
+
+	if parentPath != "" {
+		unknownFieldMessage += " in " + parentPath
+	}
+
+	if suggestion := closestConfigurationFieldName(fieldName, knownFieldNames); suggestion != "" {
+		unknownFieldMessage += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+
+	return errors.New(unknownFieldMessage)
+}
+
+func closestConfigurationFieldName(fieldName string, knownFieldNames []string) string {
+	normalizedFieldName := strings.ToLower(strings.ReplaceAll(fieldName, "-", "_"))
+	closestFieldName := ""
+	closestDistance := len(normalizedFieldName)/3 + 2
+
+	for _, knownFieldName := range knownFieldNames {
+		if distance := editDistance(normalizedFieldName, knownFieldName); distance < closestDistance {
+			closestFieldName = knownFieldName
+			closestDistance = distance
+		}
+	}
+
+	return closestFieldName
+}
+
+func editDistance(firstString string, secondString string) int {
+	previousRow := make([]int, len(secondString)+1)
+	currentRow := make([]int, len(secondString)+1)
+
+	for columnIndex := range previousRow {
+		previousRow[columnIndex] = columnIndex
+	}
+
+	for rowIndex := 1; rowIndex <= len(firstString); rowIndex++ {
+		currentRow[0] = rowIndex
+
+		for columnIndex := 1; columnIndex <= len(secondString); columnIndex++ {
+			substitutionCost := 1
+
+			if firstString[rowIndex-1] == secondString[columnIndex-1] {
+				substitutionCost = 0
+			}
+
+			currentRow[columnIndex] = min(previousRow[columnIndex]+1, currentRow[columnIndex-1]+1, previousRow[columnIndex-1]+substitutionCost)
+		}
+
+		previousRow, currentRow = currentRow, previousRow
+	}
+
+	return previousRow[len(secondString)]
+}
+
+func describeConfigurationType(valueType reflect.Type) string {
+	for valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+
+	switch valueType.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return valueType.String()
+	}
+}
+
+func newConfigurationError(configurationPath string, fileData []byte, offset int64, err error) *ConfigurationError {
+	if offset < 0 || offset > int64(len(fileData)) {
+		return &ConfigurationError{Path: configurationPath, Err: err}
+	}
+
+	precedingData := fileData[:offset]
+	lineStart := bytes.LastIndexByte(precedingData, '\n') + 1
+
+	return &ConfigurationError{
+		Path:   configurationPath,
+		Line:   bytes.Count(precedingData, []byte("\n")) + 1,
+		Column: len(precedingData) - lineStart + 1,
+		Err:    err,
+	}
+}
@@ -0,0 +1,116 @@
+package iku
+
+import (
+	"github.com/Fuwn/iku/engine"
+	"strings"
+)
+
+type formattingDirective int
+
+const (
+	noDirective formattingDirective = iota
+	directiveOff
+	directiveOn
+	directiveIgnore
+)
+
+func parseFormattingDirective(trimmedLine string) formattingDirective {
+	commentText, isLineComment := strings.CutPrefix(trimmedLine, "//")
+
+	if !isLineComment {
+		return noDirective
+	}
+
+	directiveName, isDirective := strings.CutPrefix(strings.TrimSpace(commentText), "iku:")
+
+	if !isDirective {
+		return noDirective
+	}
+
+	if fieldIndex := strings.IndexFunc(directiveName, func(character rune) bool { return character == ' ' || character == '\t' }); fieldIndex >= 0 {
+		directiveName = directiveName[:fieldIndex]
+	}
+
+	switch directiveName {
+	case "off":
+		return directiveOff
+	case "on":
+		return directiveOn
+	case "ignore":
+		return directiveIgnore
+	default:
+		return noDirective
+	}
+}
+
+func applyFormattingDirectives(events []engine.LineEvent, findStatementEnd func(events []engine.LineEvent, startIndex int) int) {
+	isFormattingDisabled := false
+
+	for eventIndex := 0; eventIndex < len(events); eventIndex++ {
+		event := &events[eventIndex]
+		directive := noDirective
+
+		if event.IsCommentOnly && !event.InRawString {
+			directive = parseFormattingDirective(event.TrimmedContent)
+		}
+
+		if isFormattingDisabled {
+			event.IsVerbatim = true
+
+			if directive == directiveOn {
+				isFormattingDisabled = false
+			}
+
+			continue
+		}
+
+		switch directive {
+		case directiveOff:
+			isFormattingDisabled = true
+		case directiveIgnore:
+			statementIndex := findNextStatement(events, eventIndex+1)
+
+			if statementIndex < 0 {
+				continue
+			}
+
+			statementEndIndex := max(findStatementEnd(events, statementIndex), statementIndex)
+
+			for verbatimIndex := eventIndex + 1; verbatimIndex <= statementEndIndex; verbatimIndex++ {
+				events[verbatimIndex].IsVerbatim = true
+			}
+
+			eventIndex = statementEndIndex
+		}
+	}
+}
+
+func findNextStatement(events []engine.LineEvent, startIndex int) int {
+	for eventIndex := startIndex; eventIndex < len(events); eventIndex++ {
+		if events[eventIndex].IsBlank || events[eventIndex].IsCommentOnly {
+			continue
+		}
+
+		return eventIndex
+	}
+
+	return -1
+}
+
+func findBracketedStatementEnd(events []engine.LineEvent, startIndex int) int {
+	bracketDepth := 0
+
+	for eventIndex := startIndex; eventIndex < len(events); eventIndex++ {
+		if events[eventIndex].InRawString {
+			continue
+		}
+
+		bracketDepth += countBracketDepthChange(events[eventIndex].Content)
+
+		if bracketDepth <= 0 {
+			return eventIndex
+		}
+	}
+
+	return len(events) - 1
+}
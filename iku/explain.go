@@ -0,0 +1,68 @@
+package iku
+
+import "github.com/Fuwn/iku/engine"
+
+type LineExplanation struct {
+	Line              int    `json:"line"`
+	Content           string `json:"content"`
+	StatementType     string `json:"statement_type,omitempty"`
+	IsTopLevel        bool   `json:"is_top_level"`
+	IsScoped          bool   `json:"is_scoped"`
+	IsComment         bool   `json:"is_comment"`
+	IsContinuation    bool   `json:"is_continuation"`
+	Blank             string `json:"blank,omitempty"`
+	Rule              string `json:"rule,omitempty"`
+	RemovedBlankLines int    `json:"removed_blank_lines,omitempty"`
+}
+
+func (f *Formatter) Explain(source []byte, filename string) ([]LineExplanation, error) {
+	formattingEngine, events, _, err := f.analyzeOriginalLayout(source, filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var lineExplanations []LineExplanation
+
+	for _, lineDecision := range formattingEngine.Explain(events) {
+		event := events[lineDecision.Line]
+		statementType := event.StatementType
+
+		if event.IsPackageDecl {
+			statementType = "package"
+		}
+
+		if lineDecision.Rule != "" {
+			for keptIndex := len(lineExplanations) - 1; keptIndex >= 0 && lineExplanations[keptIndex].Blank == "preserved"; keptIndex-- {
+				lineExplanations[keptIndex].Blank = "kept"
+				lineExplanations[keptIndex].Rule = lineDecision.Rule
+			}
+		}
+
+		for range lineDecision.InsertedBlankLines {
+			lineExplanations = append(lineExplanations, LineExplanation{Line: len(lineExplanations) + 1, Blank: "inserted", Rule: lineDecision.Rule})
+		}
+
+		lineExplanation := LineExplanation{
+			Line:              len(lineExplanations) + 1,
+			Content:           event.Content,
+			StatementType:     statementType,
+			IsTopLevel:        event.HasASTInfo && event.IsTopLevel,
+			IsScoped:          lineDecision.IsScoped,
+			IsComment:         event.IsCommentOnly,
+			IsContinuation:    event.IsContinuation,
+			RemovedBlankLines: lineDecision.RemovedBlankLines,
+		}
+
+		switch {
+		case event.IsBlank && !event.InRawString:
+			lineExplanation.Blank = "preserved"
+		case lineDecision.RemovedBlankLines > 0:
+			lineExplanation.Rule = engine.RuleExtraBlank
+		}
+
+		lineExplanations = append(lineExplanations, lineExplanation)
+	}
+
+	return lineExplanations, nil
+}
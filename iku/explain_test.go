@@ -0,0 +1,57 @@
+package iku
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	source := "package main\n\nfunc main() {\n\tx := 1\n\n\n\tx++\n\tif x > 0 {\n\t}\n}\n"
+	lineExplanations, err := Explain([]byte(source), "main.go", Options{})
+
+	if err != nil {
+		t.Fatalf("Explain error: %v", err)
+	}
+
+	expected := []LineExplanation{
+		{Line: 1, Content: "package main", StatementType: "package"},
+		{Line: 2, Blank: "kept", Rule: "scope-boundary"},
+		{Line: 3, Content: "func main() {", StatementType: "func", IsTopLevel: true, IsScoped: true},
+		{Line: 4, Content: "\tx := 1", StatementType: "*ast.AssignStmt"},
+		{Line: 5, Blank: "kept", Rule: "type-transition"},
+		{Line: 6, Content: "\tx++", StatementType: "*ast.IncDecStmt", Rule: "extra-blank", RemovedBlankLines: 1},
+		{Line: 7, Blank: "inserted", Rule: "scope-boundary"},
+		{Line: 8, Content: "\tif x > 0 {", StatementType: "*ast.IfStmt", IsScoped: true},
+		{Line: 9, Content: "\t}", StatementType: "*ast.IfStmt", IsScoped: true},
+		{Line: 10, Content: "}", StatementType: "func", IsTopLevel: true, IsScoped: true},
+	}
+
+	if !reflect.DeepEqual(lineExplanations, expected) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", lineExplanations, expected)
+	}
+}
+
+func TestExplainOriginalBlankLines(t *testing.T) {
+	source := "package main\n\nimport \"fmt\"\nfunc main() {\n\tx := 1\n\t\n\tfmt.Println(x)\n}\n"
+	lineExplanations, err := Explain([]byte(source), "main.go", Options{})
+
+	if err != nil {
+		t.Fatalf("Explain error: %v", err)
+	}
+
+	expected := []LineExplanation{
+		{Line: 1, Content: "package main", StatementType: "package"},
+		{Line: 2, Blank: "kept", Rule: "type-transition"},
+		{Line: 3, Content: "import \"fmt\"", StatementType: "import", IsTopLevel: true},
+		{Line: 4, Blank: "inserted", Rule: "top-level-transition"},
+		{Line: 5, Content: "func main() {", StatementType: "func", IsTopLevel: true, IsScoped: true},
+		{Line: 6, Content: "\tx := 1", StatementType: "*ast.AssignStmt"},
+		{Line: 7, Blank: "kept", Rule: "type-transition"},
+		{Line: 8, Content: "\tfmt.Println(x)", StatementType: "*ast.ExprStmt"},
+		{Line: 9, Content: "}", StatementType: "func", IsTopLevel: true, IsScoped: true},
+	}
+
+	if !reflect.DeepEqual(lineExplanations, expected) {
+		t.Errorf("got:\n%+v\nwant:\n%+v", lineExplanations, expected)
+	}
+}
@@ -0,0 +1,203 @@
+package iku
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/Fuwn/iku/engine"
+	"github.com/Fuwn/iku/internal/linediff"
+	"strings"
+)
+
+type CommentMode int
+
+const (
+	CommentsFollow CommentMode = iota
+	CommentsPrecede
+	CommentsStandalone
+)
+
+type Formatter struct {
+	CommentMode   CommentMode
+	Configuration Configuration
+	LineRanges    []engine.LineRange
+	Language      string
+}
+
+type lineInformation struct {
+	statementType string
+	isTopLevel    bool
+	isScoped      bool
+	scopeKind     string
+	isStartLine   bool
+	endLine       int
+}
+
+func (f *Formatter) Format(source []byte, filename string) ([]byte, error) {
+	formattingEngine, _, events, err := f.analyze(source, filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	formattedSource := formattingEngine.FormatToBytes(events)
+
+	if len(f.LineRanges) > 0 {
+		return restrictToLineRanges(source, formattedSource, f.LineRanges), nil
+	}
+
+	return formattedSource, nil
+}
+
+func (f *Formatter) analyze(source []byte, filename string) (*engine.Engine, []byte, []engine.LineEvent, error) {
+	adapter := f.adapter(source, filename)
+	configuration, commentMode := f.Configuration, f.CommentMode
+
+	if languageSection := configuration.languageSection(configurationLanguage(adapter, filename, f.Language)); languageSection != nil {
+		configuration = configuration.withLanguageSection(*languageSection)
+
+		if languageSection.CommentMode != nil {
+			languageCommentMode, err := configuration.commentMode()
+
+			if err != nil {
+				return nil, nil, nil, &ConfigurationError{Err: err}
+			}
+
+			commentMode = languageCommentMode
+		}
+	}
+
+	normalizedSource, events, err := analyzeWithAdapter(adapter, source, filename)
+
+	if err != nil {
+		var adapterError *AdapterError
+
+		if errors.As(err, &adapterError) {
+			return nil, nil, nil, fmt.Errorf("%s: %w", filename, err)
+		}
+
+		return nil, nil, nil, &ParseError{Filename: filename, Err: err}
+	}
+
+	topLevelBlankLines, scopeBoundaryBlankLines, typeTransitionBlankLines := configuration.blankLineCounts()
+	formattingEngine := &engine.Engine{
+		CommentMode:              MapCommentMode(commentMode),
+		GroupSingleLineScopes:    configuration.GroupSingleLineFunctions,
+		StatementGroups:          configuration.statementGroups(),
+		ScopedKinds:              configuration.scopedKinds(),
+		PreserveBlankLines:       configuration.PreserveBlankLines,
+		TopLevelBlankLines:       topLevelBlankLines,
+		ScopeBoundaryBlankLines:  scopeBoundaryBlankLines,
+		TypeTransitionBlankLines: typeTransitionBlankLines,
+		LineRanges:               remapLineRanges(source, normalizedSource, f.LineRanges),
+	}
+
+	return formattingEngine, normalizedSource, events, nil
+}
+
+func (f *Formatter) adapter(source []byte, filename string) Adapter {
+	if externalAdapter := f.Configuration.externalAdapterFor(filename); externalAdapter != nil {
+		return externalAdapter
+	}
+
+	return SelectAdapter(source, filename, f.Language)
+}
+
+func analyzeWithAdapter(adapter Adapter, source []byte, filename string) ([]byte, []engine.LineEvent, error) {
+	if externalAdapter, isExternal := adapter.(*ExternalAdapter); isExternal {
+		return externalAdapter.analyze(source, filename)
+	}
+
+	return adapter.Analyze(source)
+}
+
+func isGeneratedSource(source []byte, filename string) bool {
+	generatedSourceDetector, isDetector := SelectAdapter(source, filename, "").(GeneratedSourceDetector)
+
+	return isDetector && generatedSourceDetector.IsGenerated(source)
+}
+
+func remapLineRanges(originalSource, normalizedSource []byte, lineRanges []engine.LineRange) []engine.LineRange {
+	if len(lineRanges) == 0 || bytes.Equal(originalSource, normalizedSource) {
+		return lineRanges
+	}
+
+	originalLines := linediff.SplitLines(originalSource)
+	normalizedLineNumbers := make([]int, len(originalLines)+1)
+
+	for _, operation := range linediff.Compute(lineTokens(originalLines), lineTokens(linediff.SplitLines(normalizedSource))) {
+		if operation.Kind != linediff.Insert {
+			normalizedLineNumbers[operation.OriginalIndex] = operation.FormattedIndex + 1
+		}
+	}
+
+	normalizedLineNumbers[len(originalLines)] = len(linediff.SplitLines(normalizedSource)) + 1
+	remappedLineRanges := make([]engine.LineRange, 0, len(lineRanges))
+
+	for _, lineRange := range lineRanges {
+		startLine := normalizedLineNumbers[min(lineRange.Start, len(originalLines)+1)-1]
+		endLine := normalizedLineNumbers[min(lineRange.End, len(originalLines)+1)-1]
+		remappedLineRanges = append(remappedLineRanges, engine.LineRange{Start: startLine, End: max(startLine, endLine)})
+	}
+
+	return remappedLineRanges
+}
+
+func restrictToLineRanges(originalSource, formattedSource []byte, lineRanges []engine.LineRange) []byte {
+	originalLines := linediff.SplitLines(originalSource)
+	formattedLines := linediff.SplitLines(formattedSource)
+	originalTokens := lineTokens(originalLines)
+
+	var restrictedSource bytes.Buffer
+
+	for _, operation := range linediff.Compute(originalTokens, lineTokens(formattedLines)) {
+		isInRange := isOriginalGapInRange(originalTokens, operation.OriginalIndex, lineRanges)
+
+		switch {
+		case operation.Kind == linediff.Insert && isInRange:
+			restrictedSource.WriteString(formattedLines[operation.FormattedIndex])
+		case operation.Kind == linediff.Delete && !isInRange:
+			restrictedSource.WriteString(originalLines[operation.OriginalIndex])
+		case operation.Kind == linediff.Equal && isInRange:
+			restrictedSource.WriteString(formattedLines[operation.FormattedIndex])
+		case operation.Kind == linediff.Equal:
+			restrictedSource.WriteString(originalLines[operation.OriginalIndex])
+		}
+	}
+
+	return restrictedSource.Bytes()
+}
+
+func lineTokens(sourceLines []string) []string {
+	tokens := make([]string, len(sourceLines))
+
+	for lineIndex, sourceLine := range sourceLines {
+		tokens[lineIndex] = strings.Join(strings.Fields(sourceLine), "")
+	}
+
+	return tokens
+}
+
+func isOriginalGapInRange(originalTokens []string, originalIndex int, lineRanges []engine.LineRange) bool {
+	for ; originalIndex < len(originalTokens); originalIndex++ {
+		if isLineInRanges(originalIndex+1, lineRanges) {
+			return true
+		}
+
+		if originalTokens[originalIndex] != "" {
+			return false
+		}
+	}
+
+	return isLineInRanges(originalIndex+1, lineRanges)
+}
+
+func isLineInRanges(lineNumber int, lineRanges []engine.LineRange) bool {
+	for _, lineRange := range lineRanges {
+		if lineNumber >= lineRange.Start && lineNumber <= lineRange.End {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,771 @@
+package iku
+
+import (
+	"fmt"
+	"github.com/Fuwn/iku/engine"
+	"strings"
+	"testing"
+)
+
+func TestFormatRemovesExtraBlankLines(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	x := 1
+
+
+	y := 2
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	x := 1
+	y := 2
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatScopedStatements(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	x := 1
+	if x > 0 {
+		y := 2
+	}
+	z := 3
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	x := 1
+
+	if x > 0 {
+		y := 2
+	}
+
+	z := 3
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatNestedScopes(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	if true {
+		x := 1
+		if false {
+			y := 2
+		}
+		z := 3
+	}
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	if true {
+		x := 1
+
+		if false {
+			y := 2
+		}
+
+		z := 3
+	}
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatForLoop(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	x := 1
+	for i := 0; i < 10; i++ {
+		y := i
+	}
+	z := 2
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	x := 1
+
+	for i := 0; i < 10; i++ {
+		y := i
+	}
+
+	z := 2
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatSwitch(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	x := 1
+	switch x {
+	case 1:
+		y := 2
+	}
+	z := 3
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	x := 1
+
+	switch x {
+	case 1:
+		y := 2
+	}
+
+	z := 3
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatMultipleFunctions(t *testing.T) {
+	inputSource := `package main
+
+func foo() {
+	x := 1
+}
+
+
+func bar() {
+	y := 2
+}
+`
+	expectedOutput := `package main
+
+func foo() {
+	x := 1
+}
+
+func bar() {
+	y := 2
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatTypeStruct(t *testing.T) {
+	inputSource := `package main
+
+type Foo struct {
+	X int
+}
+var x = 1
+`
+	expectedOutput := `package main
+
+type Foo struct {
+	X int
+}
+
+var x = 1
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatDifferentStatementTypes(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	x := 1
+	y := 2
+	var a = 3
+	defer cleanup()
+	defer cleanup2()
+	go worker()
+	return
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	x := 1
+	y := 2
+
+	var a = 3
+
+	defer cleanup()
+	defer cleanup2()
+
+	go worker()
+
+	return
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatConsecutiveIfs(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	if err != nil {
+		return
+	}
+	if x > 0 {
+		y = 1
+	}
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	if err != nil {
+		return
+	}
+
+	if x > 0 {
+		y = 1
+	}
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatCaseClauseStatements(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	switch x {
+	case 1:
+		foo()
+		if err != nil {
+			return
+		}
+	}
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	switch x {
+	case 1:
+		foo()
+
+		if err != nil {
+			return
+		}
+	}
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatDeferInlineFunc(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	defer func() { _ = file.Close() }()
+	fileInfo, err := file.Stat()
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	defer func() { _ = file.Close() }()
+
+	fileInfo, err := file.Stat()
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatCaseClauseAssignments(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	switch x {
+	case "user":
+		roleStyle = UserStyle
+		contentStyle = ContentStyle
+		prefix = "You"
+	case "assistant":
+		roleStyle = AssistantStyle
+	}
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	switch x {
+	case "user":
+		roleStyle = UserStyle
+		contentStyle = ContentStyle
+		prefix = "You"
+	case "assistant":
+		roleStyle = AssistantStyle
+	}
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatDirectives(t *testing.T) {
+	inputSource := `package main
+
+func main() {
+	x := 1
+	// iku:ignore
+	cases := []int{
+		1,
+
+		2,
+	}
+	// iku:off
+	a := 1
+
+	b := 2
+	// iku:on
+	c := 3
+
+
+	d := 4
+}
+`
+	expectedOutput := `package main
+
+func main() {
+	x := 1
+	// iku:ignore
+	cases := []int{
+		1,
+
+		2,
+	}
+	// iku:off
+	a := 1
+
+	b := 2
+	// iku:on
+	c := 3
+	d := 4
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatLineRanges(t *testing.T) {
+	inputSource := `package main
+func main() {
+	x := 1
+	if x > 0 {
+	}
+	y := 2
+	if y > 0 {
+	}
+}
+`
+	expectedOutput := `package main
+func main() {
+	x := 1
+	if x > 0 {
+	}
+	y := 2
+
+	if y > 0 {
+	}
+}
+`
+	formatter := &Formatter{CommentMode: CommentsFollow, LineRanges: []engine.LineRange{{Start: 7, End: 8}}}
+	formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatLineRangesLeavesOtherLinesUnnormalized(t *testing.T) {
+	inputSource := "package main\nfunc main() {\n  a:=1\n  b:=2\n  if a>b {\n  }\n\n\n  c:=3\n}"
+	cases := []struct {
+		name           string
+		lineRanges     []engine.LineRange
+		expectedOutput string
+	}{
+		{"statement line", []engine.LineRange{{Start: 5, End: 5}}, "package main\nfunc main() {\n  a:=1\n  b:=2\n\n\tif a > b {\n  }\n\n\n  c:=3\n}"},
+		{"blank line in gap", []engine.LineRange{{Start: 8, End: 8}}, "package main\nfunc main() {\n  a:=1\n  b:=2\n  if a>b {\n  }\n\n  c:=3\n}"},
+		{"last line", []engine.LineRange{{Start: 10, End: 10}}, "package main\nfunc main() {\n  a:=1\n  b:=2\n  if a>b {\n  }\n\n\n  c:=3\n}\n"},
+	}
+
+	for _, testCase := range cases {
+		formatter := &Formatter{CommentMode: CommentsFollow, LineRanges: testCase.lineRanges}
+		formattedResult, err := formatter.Format([]byte(inputSource), "test.go")
+
+		if err != nil {
+			t.Fatalf("%s: Format error: %v", testCase.name, err)
+		}
+
+		if string(formattedResult) != testCase.expectedOutput {
+			t.Errorf("%s: got:\n%q\nwant:\n%q", testCase.name, formattedResult, testCase.expectedOutput)
+		}
+	}
+}
+
+func TestParseFormattingDirective(t *testing.T) {
+	cases := []struct {
+		input             string
+		expectedDirective formattingDirective
+	}{
+		{"// iku:off", directiveOff},
+		{"//iku:on", directiveOn},
+		{"// iku:ignore keep the table grouping", directiveIgnore},
+		{"// iku:offset", noDirective},
+		{"// iku:", noDirective},
+		{"/* iku:off */", noDirective},
+		{"// see iku:off", noDirective},
+	}
+
+	for _, testCase := range cases {
+		if directive := parseFormattingDirective(testCase.input); directive != testCase.expectedDirective {
+			t.Errorf("parseFormattingDirective(%q) = %v, want %v", testCase.input, directive, testCase.expectedDirective)
+		}
+	}
+}
+
+func TestIsGeneratedSource(t *testing.T) {
+	cases := []struct {
+		filename          string
+		source            string
+		expectedGenerated bool
+	}{
+		{"types.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage types\n", true},
+		{"types.go", "// Copyright 2024\n\n// Code generated by stringer; DO NOT EDIT.\n\npackage types\n", true},
+		{"types.go", "package types\n\n// Code generated by stringer; DO NOT EDIT.\n", false},
+		{"types.go", "// Code generated by hand, safe to edit.\n\npackage types\n", false},
+		{"types.ts", "// Code generated by tool. DO NOT EDIT.\n", false},
+	}
+
+	for _, testCase := range cases {
+		if isGenerated := isGeneratedSource([]byte(testCase.source), testCase.filename); isGenerated != testCase.expectedGenerated {
+			t.Errorf("isGeneratedSource(%q, %q) = %v, want %v", testCase.source, testCase.filename, isGenerated, testCase.expectedGenerated)
+		}
+	}
+}
+
+func TestFormatLanguageSections(t *testing.T) {
+	groupSingleLineFunctions, precedeCommentMode := true, "precede"
+	configuration := Configuration{
+		Go:         &LanguageConfiguration{GroupSingleLineFunctions: &groupSingleLineFunctions},
+		TypeScript: &LanguageConfiguration{CommentMode: &precedeCommentMode},
+	}
+	ecmaScriptSource := "const a = 1;\n// note\nif (a) {\n}\n"
+	cases := []struct {
+		filename           string
+		languageIdentifier string
+		source             string
+		expectedOutput     string
+	}{
+		{"main.go", "", "package main\n\nfunc A() int { return 1 }\nfunc B() int { return 2 }\n", "package main\n\nfunc A() int { return 1 }\nfunc B() int { return 2 }\n"},
+		{"app.ts", "", ecmaScriptSource, "const a = 1;\n// note\n\nif (a) {\n}\n"},
+		{"app.tsx", "", ecmaScriptSource, "const a = 1;\n// note\n\nif (a) {\n}\n"},
+		{"app.js", "", ecmaScriptSource, "const a = 1;\n\n// note\nif (a) {\n}\n"},
+		{"untitled", "typescript", ecmaScriptSource, "const a = 1;\n// note\n\nif (a) {\n}\n"},
+	}
+
+	for _, testCase := range cases {
+		formattedResult, err := Format([]byte(testCase.source), testCase.filename, Options{Configuration: configuration, Language: testCase.languageIdentifier})
+
+		if err != nil {
+			t.Fatalf("%s: Format error: %v", testCase.filename, err)
+		}
+
+		if string(formattedResult) != testCase.expectedOutput {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", testCase.filename, formattedResult, testCase.expectedOutput)
+		}
+	}
+}
+
+func TestFormatScopedKinds(t *testing.T) {
+	goSource := "package main\n\nfunc main() {\n\tdefer cleanup()\n\tdefer func() {\n\t\tcleanup()\n\t}()\n\tselect {\n\tdefault:\n\t}\n\tselect {\n\tdefault:\n\t}\n}\n"
+	cases := []struct {
+		name           string
+		configuration  Configuration
+		filename       string
+		source         string
+		expectedOutput string
+	}{
+		{
+			"default kinds",
+			Configuration{},
+			"main.go",
+			goSource,
+			"package main\n\nfunc main() {\n\tdefer cleanup()\n\tdefer func() {\n\t\tcleanup()\n\t}()\n\n\tselect {\n\tdefault:\n\t}\n\n\tselect {\n\tdefault:\n\t}\n}\n",
+		},
+		{
+			"defer func scoped and select unscoped",
+			Configuration{ScopedKinds: []string{"if", "func", "defer_func"}},
+			"main.go",
+			goSource,
+			"package main\n\nfunc main() {\n\tdefer cleanup()\n\n\tdefer func() {\n\t\tcleanup()\n\t}()\n\n\tselect {\n\tdefault:\n\t}\n\tselect {\n\tdefault:\n\t}\n}\n",
+		},
+		{
+			"select single case unscoped",
+			Configuration{ScopedKinds: []string{"if", "func", "select"}},
+			"main.go",
+			"package main\n\nfunc main() {\n\tselect {\n\tdefault:\n\t}\n\tselect {\n\tdefault:\n\t}\n\tselect {\n\tcase <-c:\n\tdefault:\n\t}\n}\n",
+			"package main\n\nfunc main() {\n\tselect {\n\tdefault:\n\t}\n\tselect {\n\tdefault:\n\t}\n\n\tselect {\n\tcase <-c:\n\tdefault:\n\t}\n}\n",
+		},
+		{
+			"language section",
+			Configuration{ScopedKinds: []string{"if", "func"}, JavaScript: &LanguageConfiguration{ScopedKinds: []string{"function"}}},
+			"app.js",
+			"function b() {\n  const a = 1;\n  if (a) {\n  }\n  const c = 2;\n}\n",
+			"function b() {\n  const a = 1;\n\n  if (a) {\n  }\n  const c = 2;\n}\n",
+		},
+		{
+			"braces in strings and comments",
+			Configuration{JavaScript: &LanguageConfiguration{ScopedKinds: []string{"function"}}},
+			"app.js",
+			"function b() {\n  if (a) {\n    x(\"}\");\n  }\n  if (c) {\n    x('{', `{`); // }\n  }\n  if (d) {\n  }\n}\n",
+			"function b() {\n  if (a) {\n    x(\"}\");\n  }\n  if (c) {\n    x('{', `{`); // }\n  }\n  if (d) {\n  }\n}\n",
+		},
+	}
+
+	for _, testCase := range cases {
+		formattedResult, err := Format([]byte(testCase.source), testCase.filename, Options{Configuration: testCase.configuration})
+
+		if err != nil {
+			t.Fatalf("%s: Format error: %v", testCase.name, err)
+		}
+
+		if string(formattedResult) != testCase.expectedOutput {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", testCase.name, formattedResult, testCase.expectedOutput)
+		}
+	}
+}
+
+func TestFormatPreserveBlankLines(t *testing.T) {
+	disabled := false
+	source := "package main\n\nfunc main() {\n\ta := 1\n\n\n\tb := 2\n\tprintln(a, b)\n}\n"
+	cases := []struct {
+		name           string
+		configuration  Configuration
+		expectedOutput string
+	}{
+		{"default", Configuration{}, "package main\n\nfunc main() {\n\ta := 1\n\tb := 2\n\n\tprintln(a, b)\n}\n"},
+		{"enabled", Configuration{PreserveBlankLines: true}, "package main\n\nfunc main() {\n\ta := 1\n\n\tb := 2\n\n\tprintln(a, b)\n}\n"},
+		{"disabled by language section", Configuration{PreserveBlankLines: true, Go: &LanguageConfiguration{PreserveBlankLines: &disabled}}, "package main\n\nfunc main() {\n\ta := 1\n\tb := 2\n\n\tprintln(a, b)\n}\n"},
+	}
+
+	for _, testCase := range cases {
+		formattedResult, err := Format([]byte(source), "main.go", Options{Configuration: testCase.configuration})
+
+		if err != nil {
+			t.Fatalf("%s: Format error: %v", testCase.name, err)
+		}
+
+		if string(formattedResult) != testCase.expectedOutput {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", testCase.name, formattedResult, testCase.expectedOutput)
+		}
+	}
+}
+
+func TestFormatBlankLineCounts(t *testing.T) {
+	two := 2
+	source := "package main\n\nfunc a() {\n\tx := 1\n\tif x > 0 {\n\t\treturn\n\t}\n\tprintln(x)\n}\nfunc b() {}\n"
+	cases := []struct {
+		name           string
+		filename       string
+		source         string
+		configuration  Configuration
+		expectedOutput string
+	}{
+		{"default", "main.go", source, Configuration{}, "package main\n\nfunc a() {\n\tx := 1\n\n\tif x > 0 {\n\t\treturn\n\t}\n\n\tprintln(x)\n}\n\nfunc b() {}\n"},
+		{"top level", "main.go", source, Configuration{BlankLines: &BlankLineConfiguration{TopLevel: &two}}, "package main\n\nfunc a() {\n\tx := 1\n\n\tif x > 0 {\n\t\treturn\n\t}\n\n\tprintln(x)\n}\n\n\nfunc b() {}\n"},
+		{"scope boundary by language section", "main.go", source, Configuration{Go: &LanguageConfiguration{BlankLines: &BlankLineConfiguration{ScopeBoundary: &two}}}, "package main\n\nfunc a() {\n\tx := 1\n\n\n\tif x > 0 {\n\t\treturn\n\t}\n\n\n\tprintln(x)\n}\n\nfunc b() {}\n"},
+		{"package clause", "main.go", "package main\nimport \"fmt\"\nfunc a() { fmt.Println() }\n", Configuration{BlankLines: &BlankLineConfiguration{TopLevel: &two, ScopeBoundary: &two, TypeTransition: &two}}, "package main\n\nimport \"fmt\"\n\n\nfunc a() { fmt.Println() }\n"},
+		{"type transition", "main.js", "function f() {\n  const a = 1;\n  let b = 2;\n}\n", Configuration{BlankLines: &BlankLineConfiguration{TypeTransition: &two}}, "function f() {\n  const a = 1;\n\n\n  let b = 2;\n}\n"},
+	}
+
+	for _, testCase := range cases {
+		formattedResult, err := Format([]byte(testCase.source), testCase.filename, Options{Configuration: testCase.configuration})
+
+		if err != nil {
+			t.Fatalf("%s: Format error: %v", testCase.name, err)
+		}
+
+		if string(formattedResult) != testCase.expectedOutput {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", testCase.name, formattedResult, testCase.expectedOutput)
+		}
+	}
+}
+
+func BenchmarkFormatSmall(b *testing.B) {
+	inputSource := []byte(`package main
+func main() {
+	x := 1
+	y := 2
+	if x > 0 {
+		z := 3
+	}
+	a := 4
+}
+`)
+	formatter := &Formatter{CommentMode: CommentsFollow}
+
+	for b.Loop() {
+		_, _ = formatter.Format(inputSource, "test.go")
+	}
+}
+
+func BenchmarkFormatLarge(b *testing.B) {
+	var sourceBuilder strings.Builder
+
+	sourceBuilder.WriteString("package main\n\n")
+
+	for functionIndex := range 100 {
+		fmt.Fprintf(&sourceBuilder, "func foo%d", functionIndex)
+		sourceBuilder.WriteString("() {\n")
+		sourceBuilder.WriteString("\tx := 1\n")
+		sourceBuilder.WriteString("\tif x > 0 {\n")
+		sourceBuilder.WriteString("\t\ty := 2\n")
+		sourceBuilder.WriteString("\t}\n")
+		sourceBuilder.WriteString("\tz := 3\n")
+		sourceBuilder.WriteString("}\n\n")
+	}
+
+	inputSource := []byte(sourceBuilder.String())
+	formatter := &Formatter{CommentMode: CommentsFollow}
+
+	for b.Loop() {
+		_, _ = formatter.Format(inputSource, "test.go")
+	}
+}
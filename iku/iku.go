@@ -0,0 +1,91 @@
+package iku
+
+import (
+	"fmt"
+	"github.com/Fuwn/iku/engine"
+)
+
+type Options struct {
+	Configuration Configuration
+	LineRanges    []engine.LineRange
+	Language      string
+}
+
+type ParseError struct {
+	Filename string
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Filename, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+type ConfigurationError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ConfigurationError) Error() string {
+	switch {
+	case e.Path == "":
+		return e.Err.Error()
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+	default:
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+}
+
+func (e *ConfigurationError) Unwrap() error {
+	return e.Err
+}
+
+func Format(source []byte, filename string, options Options) ([]byte, error) {
+	formatter, err := newFormatter(options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return formatter.Format(source, filename)
+}
+
+func Lint(source []byte, filename string, options Options) ([]Diagnostic, error) {
+	formatter, err := newFormatter(options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return formatter.Lint(source, filename)
+}
+
+func Explain(source []byte, filename string, options Options) ([]LineExplanation, error) {
+	formatter, err := newFormatter(options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return formatter.Explain(source, filename)
+}
+
+func newFormatter(options Options) (*Formatter, error) {
+	if err := options.Configuration.validate(); err != nil {
+		return nil, &ConfigurationError{Err: err}
+	}
+
+	commentMode, _ := options.Configuration.commentMode()
+
+	return &Formatter{CommentMode: commentMode, Configuration: options.Configuration, LineRanges: options.LineRanges, Language: options.Language}, nil
+}
+
+func IsGenerated(source []byte, filename string) bool {
+	return isGeneratedSource(source, filename)
+}
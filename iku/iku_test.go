@@ -0,0 +1,33 @@
+package iku
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatPublicAPI(t *testing.T) {
+	formattedResult, err := Format([]byte("package main\nfunc main() {\n\tx := 1\n\tif x > 0 {\n\t}\n}\n"), "main.go", Options{})
+
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	expectedOutput := "package main\n\nfunc main() {\n\tx := 1\n\n\tif x > 0 {\n\t}\n}\n"
+
+	if string(formattedResult) != expectedOutput {
+		t.Errorf("got:\n%s\nwant:\n%s", formattedResult, expectedOutput)
+	}
+}
+
+func TestFormatTypedErrors(t *testing.T) {
+	var parseError *ParseError
+	var configurationError *ConfigurationError
+
+	if _, err := Format([]byte("package main\nfunc {"), "broken.go", Options{}); !errors.As(err, &parseError) || parseError.Filename != "broken.go" {
+		t.Errorf("expected *ParseError for broken.go, got %v", err)
+	}
+
+	if _, err := Format([]byte("package main\n"), "main.go", Options{Configuration: Configuration{CommentMode: "sideways"}}); !errors.As(err, &configurationError) {
+		t.Errorf("expected *ConfigurationError, got %v", err)
+	}
+}
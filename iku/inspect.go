@@ -1,4 +1,4 @@
-package main
+package iku
 
 import (
 	"fmt"
@@ -6,17 +6,58 @@ import (
 	"go/token"
 )
 
-func isGeneralDeclarationScoped(generalDeclaration *ast.GenDecl) bool {
+func generalDeclarationScopeKind(generalDeclaration *ast.GenDecl) string {
 	for _, specification := range generalDeclaration.Specs {
 		if typeSpecification, isTypeSpecification := specification.(*ast.TypeSpec); isTypeSpecification {
 			switch typeSpecification.Type.(type) {
-			case *ast.StructType, *ast.InterfaceType:
-				return true
+			case *ast.StructType:
+				return "struct"
+			case *ast.InterfaceType:
+				return "interface"
 			}
 		}
 	}
 
-	return false
+	return ""
+}
+
+func statementScopeKind(tokenFile *token.File, statement ast.Stmt) string {
+	switch typedStatement := statement.(type) {
+	case *ast.IfStmt:
+		return "if"
+	case *ast.ForStmt:
+		return "for"
+	case *ast.RangeStmt:
+		return "range"
+	case *ast.SwitchStmt:
+		return "switch"
+	case *ast.TypeSwitchStmt:
+		return "type_switch"
+	case *ast.SelectStmt:
+		if len(typedStatement.Body.List) == 1 {
+			return "select_single_case"
+		}
+
+		return "select"
+	case *ast.BlockStmt:
+		return "block"
+	case *ast.DeferStmt:
+		if isMultiLineFunctionLiteralCall(tokenFile, typedStatement.Call) {
+			return "defer_func"
+		}
+	case *ast.GoStmt:
+		if isMultiLineFunctionLiteralCall(tokenFile, typedStatement.Call) {
+			return "go_func"
+		}
+	}
+
+	return ""
+}
+
+func isMultiLineFunctionLiteralCall(tokenFile *token.File, callExpression *ast.CallExpr) bool {
+	_, isFunctionLiteral := callExpression.Fun.(*ast.FuncLit)
+
+	return isFunctionLiteral && tokenFile.Line(callExpression.Pos()) != tokenFile.Line(callExpression.End())
 }
 
 func (f *Formatter) buildLineInfo(tokenFileSet *token.FileSet, parsedFile *ast.File) map[int]*lineInformation {
@@ -31,23 +72,24 @@ func (f *Formatter) buildLineInfo(tokenFileSet *token.FileSet, parsedFile *ast.F
 		startLine := tokenFile.Line(declaration.Pos())
 		endLine := tokenFile.Line(declaration.End())
 		statementType := ""
-		isScoped := false
+		scopeKind := ""
 
 		switch typedDeclaration := declaration.(type) {
 		case *ast.GenDecl:
 			statementType = typedDeclaration.Tok.String()
-			isScoped = isGeneralDeclarationScoped(typedDeclaration)
+			scopeKind = generalDeclarationScopeKind(typedDeclaration)
 		case *ast.FuncDecl:
 			statementType = "func"
-			isScoped = true
+			scopeKind = "func"
 		default:
 			statementType = fmt.Sprintf("%T", declaration)
 		}
 
-		lineInformationMap[startLine] = &lineInformation{statementType: statementType, isTopLevel: true, isScoped: isScoped, isStartLine: true}
+		isScoped := isDefaultGoScopeKind(scopeKind)
+		lineInformationMap[startLine] = &lineInformation{statementType: statementType, isTopLevel: true, isScoped: isScoped, scopeKind: scopeKind, isStartLine: true, endLine: endLine}
 
 		if endLine != startLine {
-			lineInformationMap[endLine] = &lineInformation{statementType: statementType, isTopLevel: true, isScoped: isScoped, isStartLine: false}
+			lineInformationMap[endLine] = &lineInformation{statementType: statementType, isTopLevel: true, isScoped: isScoped, scopeKind: scopeKind, isStartLine: false, endLine: endLine}
 		}
 	}
 
@@ -83,34 +125,26 @@ func (f *Formatter) processStatementList(tokenFile *token.File, statements []ast
 	for _, statement := range statements {
 		startLine := tokenFile.Line(statement.Pos())
 		endLine := tokenFile.Line(statement.End())
-		statementType := ""
-		isScoped := false
+		statementType := fmt.Sprintf("%T", statement)
+		scopeKind := statementScopeKind(tokenFile, statement)
+		isScoped := isDefaultGoScopeKind(scopeKind)
 
-		switch typedStatement := statement.(type) {
-		case *ast.DeclStmt:
-			if generalDeclaration, isGeneralDeclaration := typedStatement.Decl.(*ast.GenDecl); isGeneralDeclaration {
+		if declarationStatement, isDeclarationStatement := statement.(*ast.DeclStmt); isDeclarationStatement {
+			if generalDeclaration, isGeneralDeclaration := declarationStatement.Decl.(*ast.GenDecl); isGeneralDeclaration {
 				statementType = generalDeclaration.Tok.String()
-			} else {
-				statementType = fmt.Sprintf("%T", statement)
 			}
-		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt,
-			*ast.TypeSwitchStmt, *ast.SelectStmt, *ast.BlockStmt:
-			statementType = fmt.Sprintf("%T", statement)
-			isScoped = true
-		default:
-			statementType = fmt.Sprintf("%T", statement)
 		}
 
 		existingStart := lineInformationMap[startLine]
 
 		if existingStart == nil || !existingStart.isStartLine {
-			lineInformationMap[startLine] = &lineInformation{statementType: statementType, isTopLevel: false, isScoped: isScoped, isStartLine: true}
+			lineInformationMap[startLine] = &lineInformation{statementType: statementType, isTopLevel: false, isScoped: isScoped, scopeKind: scopeKind, isStartLine: true, endLine: endLine}
 		}
 
 		existingEnd := lineInformationMap[endLine]
 
 		if existingEnd == nil || !existingEnd.isStartLine {
-			lineInformationMap[endLine] = &lineInformation{statementType: statementType, isTopLevel: false, isScoped: isScoped, isStartLine: false}
+			lineInformationMap[endLine] = &lineInformation{statementType: statementType, isTopLevel: false, isScoped: isScoped, scopeKind: scopeKind, isStartLine: false, endLine: endLine}
 		}
 
 		switch typedStatement := statement.(type) {
@@ -146,13 +180,13 @@ func (f *Formatter) processIfStatement(tokenFile *token.File, ifStatement *ast.I
 	existingStart := lineInformationMap[startLine]
 
 	if existingStart == nil || !existingStart.isStartLine {
-		lineInformationMap[startLine] = &lineInformation{statementType: "*ast.IfStmt", isTopLevel: false, isScoped: true, isStartLine: true}
+		lineInformationMap[startLine] = &lineInformation{statementType: "*ast.IfStmt", isTopLevel: false, isScoped: true, scopeKind: "if", isStartLine: true, endLine: endLine}
 	}
 
 	existingEnd := lineInformationMap[endLine]
 
 	if existingEnd == nil || !existingEnd.isStartLine {
-		lineInformationMap[endLine] = &lineInformation{statementType: "*ast.IfStmt", isTopLevel: false, isScoped: true, isStartLine: false}
+		lineInformationMap[endLine] = &lineInformation{statementType: "*ast.IfStmt", isTopLevel: false, isScoped: true, scopeKind: "if", isStartLine: false, endLine: endLine}
 	}
 
 	f.processBlock(tokenFile, ifStatement.Body, lineInformationMap)
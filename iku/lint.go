@@ -0,0 +1,119 @@
+package iku
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Fuwn/iku/engine"
+	"github.com/Fuwn/iku/internal/linediff"
+	"strings"
+)
+
+type Diagnostic struct {
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s (%s)", d.Filename, d.Line, d.Column, d.Message, d.Rule)
+}
+
+func (f *Formatter) Lint(source []byte, filename string) ([]Diagnostic, error) {
+	formattingEngine, events, originalLineIndices, err := f.analyzeOriginalLayout(source, filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	engineDiagnostics := formattingEngine.Lint(events)
+	diagnostics := make([]Diagnostic, 0, len(engineDiagnostics))
+	sourceLines := strings.Split(string(source), "\n")
+
+	for _, engineDiagnostic := range engineDiagnostics {
+		lineIndex := engineDiagnostic.Line
+
+		if originalLineIndices != nil {
+			lineIndex = originalLineIndices[lineIndex]
+		}
+
+		column := 1
+
+		if engineDiagnostic.Rule != engine.RuleExtraBlank && lineIndex < len(sourceLines) {
+			column = len(sourceLines[lineIndex]) - len(strings.TrimLeft(sourceLines[lineIndex], " \t")) + 1
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Filename: filename,
+			Line:     lineIndex + 1,
+			Column:   column,
+			Rule:     engineDiagnostic.Rule,
+			Message:  engineDiagnostic.Message(),
+		})
+	}
+
+	return diagnostics, nil
+}
+
+func (f *Formatter) analyzeOriginalLayout(source []byte, filename string) (*engine.Engine, []engine.LineEvent, []int, error) {
+	formattingEngine, normalizedSource, events, err := f.analyze(source, filename)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	events, originalLineIndices := originalLayoutEvents(source, normalizedSource, events)
+
+	if originalLineIndices != nil {
+		formattingEngine.LineRanges = remapLineRanges(source, joinEventContents(events), f.LineRanges)
+	}
+
+	return formattingEngine, events, originalLineIndices, nil
+}
+
+func originalLayoutEvents(originalSource, normalizedSource []byte, events []engine.LineEvent) ([]engine.LineEvent, []int) {
+	if bytes.Equal(originalSource, normalizedSource) {
+		return events, nil
+	}
+
+	originalLines := linediff.SplitLines(originalSource)
+	normalizedLines := linediff.SplitLines(normalizedSource)
+	layoutEvents := make([]engine.LineEvent, 0, len(events))
+	originalLineIndices := make([]int, 0, len(events))
+
+	for _, operation := range linediff.Compute(lineTokens(originalLines), lineTokens(normalizedLines)) {
+		switch operation.Kind {
+		case linediff.Equal:
+			layoutEvents = append(layoutEvents, events[operation.FormattedIndex])
+			originalLineIndices = append(originalLineIndices, operation.OriginalIndex)
+		case linediff.Insert:
+			if normalizedEvent := events[operation.FormattedIndex]; !normalizedEvent.IsBlank || normalizedEvent.InRawString {
+				layoutEvents = append(layoutEvents, normalizedEvent)
+				originalLineIndices = append(originalLineIndices, operation.OriginalIndex)
+			}
+		case linediff.Delete:
+			if originalEvent := engine.NewLineEvent(strings.TrimSuffix(originalLines[operation.OriginalIndex], "\n")); originalEvent.IsBlank {
+				layoutEvents = append(layoutEvents, originalEvent)
+				originalLineIndices = append(originalLineIndices, operation.OriginalIndex)
+			}
+		}
+	}
+
+	for eventIndex := len(normalizedLines); eventIndex < len(events); eventIndex++ {
+		layoutEvents = append(layoutEvents, events[eventIndex])
+		originalLineIndices = append(originalLineIndices, len(originalLines))
+	}
+
+	return layoutEvents, originalLineIndices
+}
+
+func joinEventContents(events []engine.LineEvent) []byte {
+	eventContents := make([]string, len(events))
+
+	for eventIndex, event := range events {
+		eventContents[eventIndex] = event.Content
+	}
+
+	return []byte(strings.Join(eventContents, "\n"))
+}
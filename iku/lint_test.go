@@ -0,0 +1,64 @@
+package iku
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		name                string
+		filename            string
+		source              string
+		expectedDiagnostics []string
+	}{
+		{"clean", "main.go", "package main\n\nfunc main() {\n\tx := 1\n\n\tif x > 0 {\n\t}\n}\n", nil},
+		{
+			"missing blank lines",
+			"main.go",
+			"package main\n\nfunc main() {\n\tx := 1\n\tif x > 0 {\n\t}\n\tprintln(x)\n}\n",
+			[]string{
+				"main.go:5:2: missing blank line between *ast.AssignStmt and *ast.IfStmt at a scope boundary (scope-boundary)",
+				"main.go:7:2: missing blank line between end of *ast.IfStmt and *ast.ExprStmt at a scope boundary (scope-boundary)",
+			},
+		},
+		{
+			"blank lines changed by normalization",
+			"main.go",
+			"package main\n\n\nvar x = 1\nfunc main() {\n}\n",
+			[]string{
+				"main.go:3:1: extra blank line between package and var (extra-blank)",
+				"main.go:5:1: missing blank line between top-level var and func (top-level-transition)",
+			},
+		},
+		{
+			"ecmascript",
+			"app.js",
+			"const a = 1;\n\nconst b = 2;\nfoo();\n",
+			[]string{
+				"app.js:2:1: extra blank line between const and const (extra-blank)",
+				"app.js:4:1: missing blank line between top-level const and expression (top-level-transition)",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var diagnosticMessages []string
+
+			diagnostics, err := Lint([]byte(testCase.source), testCase.filename, Options{})
+
+			if err != nil {
+				t.Fatalf("Lint error: %v", err)
+			}
+
+			for _, diagnostic := range diagnostics {
+				diagnosticMessages = append(diagnosticMessages, diagnostic.String())
+			}
+
+			if !reflect.DeepEqual(diagnosticMessages, testCase.expectedDiagnostics) {
+				t.Errorf("got %q, want %q", diagnosticMessages, testCase.expectedDiagnostics)
+			}
+		})
+	}
+}
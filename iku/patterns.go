@@ -1,4 +1,4 @@
-package main
+package iku
 
 func isWhitespace(character byte) bool {
 	return character == ' ' || character == '\t' || character == '\n' || character == '\r' || character == '\f'
@@ -153,3 +153,51 @@ func countRawStringDelimiters(sourceLine string) int {
 
 	return delimiterCount
 }
+
+func countBracketDepthChange(sourceLine string) int {
+	depthChange := 0
+
+	scanCodeCharacters(sourceLine, func(character byte) {
+		switch character {
+		case '(', '[', '{':
+			depthChange++
+		case ')', ']', '}':
+			depthChange--
+		}
+	})
+
+	return depthChange
+}
+
+func scanCodeCharacters(sourceLine string, visitCharacter func(character byte)) {
+	var quoteCharacter byte
+
+	for characterIndex := 0; characterIndex < len(sourceLine); characterIndex++ {
+		character := sourceLine[characterIndex]
+
+		if quoteCharacter != 0 {
+			if character == '\\' && characterIndex+1 < len(sourceLine) {
+				characterIndex++
+
+				continue
+			}
+
+			if character == quoteCharacter {
+				quoteCharacter = 0
+			}
+
+			continue
+		}
+
+		switch character {
+		case '"', '\'', '`':
+			quoteCharacter = character
+		case '/':
+			if characterIndex+1 < len(sourceLine) && sourceLine[characterIndex+1] == '/' {
+				return
+			}
+		default:
+			visitCharacter(character)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package linediff
+
+import "strings"
+
+type OperationKind int
+
+const (
+	Equal OperationKind = iota
+	Delete
+	Insert
+)
+
+type Operation struct {
+	Kind           OperationKind
+	OriginalIndex  int
+	FormattedIndex int
+}
+
+func SplitLines(source []byte) []string {
+	if len(source) == 0 {
+		return nil
+	}
+
+	sourceLines := strings.SplitAfter(string(source), "\n")
+
+	if sourceLines[len(sourceLines)-1] == "" {
+		sourceLines = sourceLines[:len(sourceLines)-1]
+	}
+
+	return sourceLines
+}
+
+func Compute(originalLines, formattedLines []string) []Operation {
+	originalCount := len(originalLines)
+	formattedCount := len(formattedLines)
+	maximumDistance := originalCount + formattedCount
+	diagonalOffset := maximumDistance + 1
+	furthestReaching := make([]int, 2*maximumDistance+3)
+
+	var furthestReachingHistory [][]int
+
+	for distance := 0; distance <= maximumDistance; distance++ {
+		furthestReachingHistory = append(furthestReachingHistory, append([]int(nil), furthestReaching[diagonalOffset-distance-1:diagonalOffset+distance+2]...))
+
+		for diagonal := -distance; diagonal <= distance; diagonal += 2 {
+			var originalPosition int
+
+			if diagonal == -distance || (diagonal != distance && furthestReaching[diagonalOffset+diagonal-1] < furthestReaching[diagonalOffset+diagonal+1]) {
+				originalPosition = furthestReaching[diagonalOffset+diagonal+1]
+			} else {
+				originalPosition = furthestReaching[diagonalOffset+diagonal-1] + 1
+			}
+
+			formattedPosition := originalPosition - diagonal
+
+			for originalPosition < originalCount && formattedPosition < formattedCount && originalLines[originalPosition] == formattedLines[formattedPosition] {
+				originalPosition++
+				formattedPosition++
+			}
+
+			furthestReaching[diagonalOffset+diagonal] = originalPosition
+
+			if originalPosition >= originalCount && formattedPosition >= formattedCount {
+				return backtrack(furthestReachingHistory, originalCount, formattedCount)
+			}
+		}
+	}
+
+	return nil
+}
+
+func backtrack(furthestReachingHistory [][]int, originalCount, formattedCount int) []Operation {
+	var reversedOperations []Operation
+
+	originalPosition := originalCount
+	formattedPosition := formattedCount
+
+	for distance := len(furthestReachingHistory) - 1; distance >= 0; distance-- {
+		furthestReaching := furthestReachingHistory[distance]
+		historyOffset := distance + 1
+		diagonal := originalPosition - formattedPosition
+
+		var previousDiagonal int
+
+		if diagonal == -distance || (diagonal != distance && furthestReaching[historyOffset+diagonal-1] < furthestReaching[historyOffset+diagonal+1]) {
+			previousDiagonal = diagonal + 1
+		} else {
+			previousDiagonal = diagonal - 1
+		}
+
+		previousOriginalPosition := furthestReaching[historyOffset+previousDiagonal]
+		previousFormattedPosition := previousOriginalPosition - previousDiagonal
+
+		for originalPosition > previousOriginalPosition && formattedPosition > previousFormattedPosition {
+			originalPosition--
+			formattedPosition--
+
+			reversedOperations = append(reversedOperations, Operation{Kind: Equal, OriginalIndex: originalPosition, FormattedIndex: formattedPosition})
+		}
+
+		if distance == 0 {
+			break
+		}
+
+		if originalPosition == previousOriginalPosition {
+			formattedPosition--
+
+			reversedOperations = append(reversedOperations, Operation{Kind: Insert, OriginalIndex: originalPosition, FormattedIndex: formattedPosition})
+		} else {
+			originalPosition--
+
+			reversedOperations = append(reversedOperations, Operation{Kind: Delete, OriginalIndex: originalPosition, FormattedIndex: formattedPosition})
+		}
+	}
+
+	operations := make([]Operation, len(reversedOperations))
+
+	for operationIndex, operation := range reversedOperations {
+		operations[len(reversedOperations)-1-operationIndex] = operation
+	}
+
+	return operations
+}
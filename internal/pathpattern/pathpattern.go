@@ -0,0 +1,75 @@
+package pathpattern
+
+import (
+	"regexp"
+	"strings"
+)
+
+func Compile(patternLine string) (*regexp.Regexp, error) {
+	isAnchored := strings.Contains(patternLine, "/")
+	patternLine = strings.TrimPrefix(patternLine, "/")
+	expressionPrefix := "^"
+
+	if !isAnchored {
+		expressionPrefix = "^(?:.*/)?"
+	}
+
+	return regexp.Compile(expressionPrefix + Translate(patternLine) + "$")
+}
+
+func Translate(patternLine string) string {
+	var expressionBuilder strings.Builder
+
+	for characterIndex := 0; characterIndex < len(patternLine); characterIndex++ {
+		character := patternLine[characterIndex]
+
+		switch character {
+		case '*':
+			if characterIndex+1 < len(patternLine) && patternLine[characterIndex+1] == '*' {
+				characterIndex++
+
+				if characterIndex+1 < len(patternLine) && patternLine[characterIndex+1] == '/' {
+					characterIndex++
+
+					expressionBuilder.WriteString("(?:.*/)?")
+				} else {
+					expressionBuilder.WriteString(".*")
+				}
+
+				continue
+			}
+
+			expressionBuilder.WriteString("[^/]*")
+		case '?':
+			expressionBuilder.WriteString("[^/]")
+		case '[':
+			closingIndex := strings.IndexByte(patternLine[characterIndex+1:], ']')
+
+			if closingIndex < 0 {
+				expressionBuilder.WriteString(regexp.QuoteMeta("["))
+
+				continue
+			}
+
+			characterClass := patternLine[characterIndex+1 : characterIndex+1+closingIndex]
+
+			if strings.HasPrefix(characterClass, "!") {
+				characterClass = "^" + characterClass[1:]
+			}
+
+			expressionBuilder.WriteString("[" + strings.ReplaceAll(characterClass, "\\", "\\\\") + "]")
+
+			characterIndex += closingIndex + 1
+		case '\\':
+			if characterIndex+1 < len(patternLine) {
+				characterIndex++
+
+				expressionBuilder.WriteString(regexp.QuoteMeta(string(patternLine[characterIndex])))
+			}
+		default:
+			expressionBuilder.WriteString(regexp.QuoteMeta(string(character)))
+		}
+	}
+
+	return expressionBuilder.String()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Fuwn/iku/iku"
+	"io"
+	"os"
+)
+
+var lintMode bool
+
+func runLintCommand(arguments []string) int {
+	lintMode = true
+
+	return run(arguments)
+}
+
+func lintFile(options iku.Options, filename string, sourceContent []byte, outputWriter io.Writer) (fileStatus, error) {
+	diagnostics, err := iku.Lint(sourceContent, filename, options)
+
+	if err != nil {
+		return fileUnchanged, err
+	}
+
+	for _, diagnostic := range diagnostics {
+		if !*jsonFlag {
+			fmt.Fprintln(outputWriter, diagnostic)
+
+			continue
+		}
+
+		diagnosticContent, err := json.Marshal(diagnostic)
+
+		if err != nil {
+			return fileUnchanged, err
+		}
+
+		fmt.Fprintln(outputWriter, string(diagnosticContent))
+	}
+
+	if len(diagnostics) > 0 {
+		return fileReformatted, nil
+	}
+
+	return fileUnchanged, nil
+}
+
+func checkLintFlags() bool {
+	if *writeFlag || *listFlag || *diffFlag || *checkFlag {
+		fmt.Fprintln(os.Stderr, "iku: cannot use lint with -w, -l, -d, or -check")
+
+		return false
+	}
+
+	return true
+}
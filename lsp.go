@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Fuwn/iku/engine"
+	"github.com/Fuwn/iku/iku"
+	"github.com/Fuwn/iku/internal/linediff"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	languageServerMethodNotFound = -32601
+	languageServerInvalidParams  = -32602
+	languageServerInternalError  = -32603
+)
+
+type languageServerMessage struct {
+	JSONRPC string               `json:"jsonrpc"`
+	ID      *json.RawMessage     `json:"id,omitempty"`
+	Method  string               `json:"method,omitempty"`
+	Params  json.RawMessage      `json:"params,omitempty"`
+	Result  any                  `json:"result,omitempty"`
+	Error   *languageServerError `json:"error,omitempty"`
+}
+
+type languageServerError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type languageServerPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type languageServerRange struct {
+	Start languageServerPosition `json:"start"`
+	End   languageServerPosition `json:"end"`
+}
+
+type languageServerTextEdit struct {
+	Range   languageServerRange `json:"range"`
+	NewText string              `json:"newText"`
+}
+
+type languageServerTextDocument struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Text       string `json:"text"`
+}
+
+type languageServerDocument struct {
+	text               string
+	languageIdentifier string
+}
+
+type languageServerWorkspaceFolder struct {
+	URI string `json:"uri"`
+}
+
+type languageServerInitializeParameters struct {
+	RootURI          string                          `json:"rootUri"`
+	WorkspaceFolders []languageServerWorkspaceFolder `json:"workspaceFolders"`
+}
+
+type languageServerDidOpenParameters struct {
+	TextDocument languageServerTextDocument `json:"textDocument"`
+}
+
+type languageServerDidChangeParameters struct {
+	TextDocument   languageServerTextDocument `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type languageServerFormattingParameters struct {
+	TextDocument languageServerTextDocument `json:"textDocument"`
+	Range        *languageServerRange       `json:"range"`
+	Position     *languageServerPosition    `json:"position"`
+	Character    string                     `json:"ch"`
+}
+
+type languageServer struct {
+	reader           *bufio.Reader
+	writer           io.Writer
+	documents        map[string]languageServerDocument
+	workspaceFolders []string
+	isShutdown       bool
+}
+
+func runLanguageServerCommand(arguments []string) int {
+	if len(arguments) != 0 {
+		fmt.Fprintln(os.Stderr, "usage: iku lsp")
+
+		return 2
+	}
+
+	server := &languageServer{
+		reader:    bufio.NewReader(os.Stdin),
+		writer:    os.Stdout,
+		documents: make(map[string]languageServerDocument),
+	}
+
+	return server.serve()
+}
+
+func (s *languageServer) serve() int {
+	for {
+		message, err := s.readMessage()
+
+		if errors.Is(err, io.EOF) {
+			return 1
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "iku: lsp: %v\n", err)
+
+			return 1
+		}
+
+		if message.Method == "exit" {
+			if s.isShutdown {
+				return 0
+			}
+
+			return 1
+		}
+
+		result, responseError := s.handle(message)
+
+		if message.ID == nil {
+			continue
+		}
+
+		response := languageServerMessage{JSONRPC: "2.0", ID: message.ID, Result: result, Error: responseError}
+
+		if result == nil && responseError == nil {
+			response.Result = json.RawMessage("null")
+		}
+
+		if err := s.writeMessage(response); err != nil {
+			fmt.Fprintf(os.Stderr, "iku: lsp: %v\n", err)
+
+			return 1
+		}
+	}
+}
+
+func (s *languageServer) readMessage() (*languageServerMessage, error) {
+	contentLength := -1
+
+	for {
+		headerLine, err := s.reader.ReadString('\n')
+
+		if err != nil {
+			return nil, err
+		}
+
+		headerLine = strings.TrimRight(headerLine, "\r\n")
+
+		if headerLine == "" {
+			break
+		}
+
+		headerName, headerValue, hasSeparator := strings.Cut(headerLine, ":")
+
+		if hasSeparator && strings.EqualFold(strings.TrimSpace(headerName), "Content-Length") {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(headerValue))
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %q", headerValue)
+			}
+		}
+	}
+
+	if contentLength < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	messageContent := make([]byte, contentLength)
+
+	if _, err := io.ReadFull(s.reader, messageContent); err != nil {
+		return nil, err
+	}
+
+	var message languageServerMessage
+
+	if err := json.Unmarshal(messageContent, &message); err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+func (s *languageServer) writeMessage(message languageServerMessage) error {
+	messageContent, err := json.Marshal(message)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(messageContent), messageContent)
+
+	return err
+}
+
+func (s *languageServer) logMessage(logText string) {
+	logParameters, _ := json.Marshal(map[string]any{"type": 3, "message": logText})
+	_ = s.writeMessage(languageServerMessage{JSONRPC: "2.0", Method: "window/logMessage", Params: logParameters})
+}
+
+func (s *languageServer) handle(message *languageServerMessage) (any, *languageServerError) {
+	switch message.Method {
+	case "initialize":
+		return s.initialize(message.Params)
+	case "initialized", "$/cancelRequest", "$/setTrace", "textDocument/didSave":
+		return nil, nil
+	case "shutdown":
+		s.isShutdown = true
+
+		return nil, nil
+	case "textDocument/didOpen":
+		var parameters languageServerDidOpenParameters
+
+		if err := json.Unmarshal(message.Params, &parameters); err == nil {
+			s.documents[parameters.TextDocument.URI] = languageServerDocument{text: parameters.TextDocument.Text, languageIdentifier: parameters.TextDocument.LanguageID}
+		}
+
+		return nil, nil
+	case "textDocument/didChange":
+		var parameters languageServerDidChangeParameters
+
+		if err := json.Unmarshal(message.Params, &parameters); err == nil && len(parameters.ContentChanges) > 0 {
+			openDocument := s.documents[parameters.TextDocument.URI]
+			openDocument.text = parameters.ContentChanges[len(parameters.ContentChanges)-1].Text
+			s.documents[parameters.TextDocument.URI] = openDocument
+		}
+
+		return nil, nil
+	case "textDocument/didClose":
+		var parameters languageServerDidOpenParameters
+
+		if err := json.Unmarshal(message.Params, &parameters); err == nil {
+			delete(s.documents, parameters.TextDocument.URI)
+		}
+
+		return nil, nil
+	case "textDocument/formatting", "textDocument/rangeFormatting", "textDocument/onTypeFormatting":
+		return s.format(message.Method, message.Params)
+	}
+
+	if message.ID == nil {
+		return nil, nil
+	}
+
+	return nil, &languageServerError{Code: languageServerMethodNotFound, Message: "method not found: " + message.Method}
+}
+
+func (s *languageServer) initialize(rawParameters json.RawMessage) (any, *languageServerError) {
+	var parameters languageServerInitializeParameters
+
+	if err := json.Unmarshal(rawParameters, &parameters); err != nil {
+		return nil, &languageServerError{Code: languageServerInvalidParams, Message: err.Error()}
+	}
+
+	for _, workspaceFolder := range parameters.WorkspaceFolders {
+		if folderPath, err := uriToPath(workspaceFolder.URI); err == nil {
+			s.workspaceFolders = append(s.workspaceFolders, folderPath)
+		}
+	}
+
+	if len(s.workspaceFolders) == 0 && parameters.RootURI != "" {
+		if rootPath, err := uriToPath(parameters.RootURI); err == nil {
+			s.workspaceFolders = append(s.workspaceFolders, rootPath)
+		}
+	}
+
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":                map[string]any{"openClose": true, "change": 1},
+			"documentFormattingProvider":      true,
+			"documentRangeFormattingProvider": true,
+			"documentOnTypeFormattingProvider": map[string]any{
+				"firstTriggerCharacter": "}",
+				"moreTriggerCharacter":  []string{"\n"},
+			},
+		},
+		"serverInfo": map[string]any{"name": "iku", "version": version},
+	}, nil
+}
+
+func (s *languageServer) format(method string, rawParameters json.RawMessage) (any, *languageServerError) {
+	var parameters languageServerFormattingParameters
+
+	if err := json.Unmarshal(rawParameters, &parameters); err != nil {
+		return nil, &languageServerError{Code: languageServerInvalidParams, Message: err.Error()}
+	}
+
+	documentPath, err := uriToPath(parameters.TextDocument.URI)
+
+	if err != nil {
+		return nil, &languageServerError{Code: languageServerInvalidParams, Message: err.Error()}
+	}
+
+	openDocument, isOpen := s.documents[parameters.TextDocument.URI]
+
+	if !isOpen {
+		documentContent, err := os.ReadFile(documentPath)
+
+		if err != nil {
+			return nil, &languageServerError{Code: languageServerInternalError, Message: err.Error()}
+		}
+
+		openDocument.text = string(documentContent)
+	}
+
+	documentText := openDocument.text
+	workspaceFolder := s.workspaceFolderFor(documentPath)
+	configurationResolver := newConfigurationResolver("")
+	configurationResolver.workingDirectory = workspaceFolder
+	configuration, configurationPath, err := configurationResolver.resolve(documentPath)
+
+	if workspaceConfigurationPath := iku.FindConfigurationFile(workspaceFolder); err == nil && configurationPath == "" && workspaceConfigurationPath != "" {
+		configuration, _, err = newConfigurationResolver(workspaceConfigurationPath).resolve(documentPath)
+	}
+
+	if err != nil {
+		return nil, &languageServerError{Code: languageServerInternalError, Message: err.Error()}
+	}
+
+	firstLine, lastLine := 0, -1
+
+	switch {
+	case method == "textDocument/rangeFormatting" && parameters.Range != nil:
+		firstLine, lastLine = parameters.Range.Start.Line, parameters.Range.End.Line
+
+		if parameters.Range.End.Character == 0 && lastLine > firstLine {
+			lastLine--
+		}
+	case method == "textDocument/onTypeFormatting" && parameters.Position != nil:
+		firstLine = parameters.Position.Line
+		documentLines := strings.Split(documentText, "\n")
+
+		if parameters.Character == "\n" {
+			firstLine = max(firstLine-1, 0)
+
+			for firstLine > 0 && firstLine < len(documentLines) && strings.TrimSpace(documentLines[firstLine]) == "" {
+				firstLine--
+			}
+		}
+
+		lastLine = firstLine
+
+		for firstLine > 0 && firstLine-1 < len(documentLines) && strings.TrimSpace(documentLines[firstLine-1]) == "" {
+			firstLine--
+		}
+	}
+
+	options := iku.Options{Configuration: configuration, Language: openDocument.languageIdentifier}
+
+	if lastLine >= 0 {
+		options.LineRanges = []engine.LineRange{{Start: firstLine + 1, End: lastLine + 1}}
+	}
+
+	formattedResult, err := iku.Format([]byte(documentText), documentPath, options)
+
+	if err != nil {
+		s.logMessage(fmt.Sprintf("iku: %v", err))
+
+		return []languageServerTextEdit{}, nil
+	}
+
+	textEdits := computeTextEdits(documentText, string(formattedResult), firstLine, lastLine)
+
+	if method == "textDocument/onTypeFormatting" && parameters.Position != nil {
+		textEdits = slices.DeleteFunc(textEdits, func(textEdit languageServerTextEdit) bool {
+			cursorLine := parameters.Position.Line
+
+			return textEdit.Range.Start.Line <= cursorLine && (cursorLine < textEdit.Range.End.Line || cursorLine == textEdit.Range.End.Line && textEdit.Range.End.Character > 0)
+		})
+	}
+
+	return textEdits, nil
+}
+
+func (s *languageServer) workspaceFolderFor(documentPath string) string {
+	bestWorkspaceFolder := ""
+
+	for _, workspaceFolder := range s.workspaceFolders {
+		relativePath, err := filepath.Rel(workspaceFolder, documentPath)
+
+		if err != nil || strings.HasPrefix(relativePath, "..") {
+			continue
+		}
+
+		if len(workspaceFolder) > len(bestWorkspaceFolder) {
+			bestWorkspaceFolder = workspaceFolder
+		}
+	}
+
+	if bestWorkspaceFolder == "" {
+		return filepath.Dir(documentPath)
+	}
+
+	return bestWorkspaceFolder
+}
+
+func uriToPath(documentURI string) (string, error) {
+	parsedURI, err := url.Parse(documentURI)
+
+	if err != nil {
+		return "", err
+	}
+
+	if parsedURI.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme: %q", documentURI)
+	}
+
+	return filepath.FromSlash(parsedURI.Path), nil
+}
+
+func computeTextEdits(originalText, formattedText string, firstLine, lastLine int) []languageServerTextEdit {
+	textEdits := []languageServerTextEdit{}
+	originalLines := linediff.SplitLines([]byte(originalText))
+	formattedLines := linediff.SplitLines([]byte(formattedText))
+	operations := linediff.Compute(originalLines, formattedLines)
+
+	for operationIndex := 0; operationIndex < len(operations); {
+		if operations[operationIndex].Kind == linediff.Equal {
+			operationIndex++
+
+			continue
+		}
+
+		startLine := operations[operationIndex].OriginalIndex
+		deletedLineCount := 0
+
+		var insertedText strings.Builder
+
+		for ; operationIndex < len(operations) && operations[operationIndex].Kind != linediff.Equal; operationIndex++ {
+			if operations[operationIndex].Kind == linediff.Delete {
+				deletedLineCount++
+			} else {
+				insertedText.WriteString(formattedLines[operations[operationIndex].FormattedIndex])
+			}
+		}
+
+		if startLine+max(deletedLineCount-1, 0) < firstLine || lastLine >= 0 && startLine > lastLine {
+			continue
+		}
+
+		endPosition := languageServerPosition{Line: startLine + deletedLineCount}
+
+		if endPosition.Line == len(originalLines) && endPosition.Line > 0 && !strings.HasSuffix(originalLines[endPosition.Line-1], "\n") {
+			endPosition = languageServerPosition{Line: endPosition.Line - 1, Character: utf16Length(originalLines[endPosition.Line-1])}
+		}
+
+		textEdits = append(textEdits, languageServerTextEdit{
+			Range:   languageServerRange{Start: languageServerPosition{Line: startLine}, End: endPosition},
+			NewText: insertedText.String(),
+		})
+	}
+
+	return textEdits
+}
+
+func utf16Length(text string) int {
+	unitCount := 0
+
+	for _, character := range text {
+		if character >= 0x10000 {
+			unitCount += 2
+		} else {
+			unitCount++
+		}
+	}
+
+	return unitCount
+}
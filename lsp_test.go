@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func applyTextEdits(originalText string, textEdits []languageServerTextEdit) string {
+	originalLines := strings.SplitAfter(originalText, "\n")
+
+	var resultBuilder strings.Builder
+
+	lineIndex := 0
+
+	for _, textEdit := range textEdits {
+		for ; lineIndex < textEdit.Range.Start.Line; lineIndex++ {
+			resultBuilder.WriteString(originalLines[lineIndex])
+		}
+
+		resultBuilder.WriteString(textEdit.NewText)
+
+		lineIndex = textEdit.Range.End.Line
+
+		if textEdit.Range.End.Character > 0 {
+			lineIndex++
+		}
+	}
+
+	for ; lineIndex < len(originalLines); lineIndex++ {
+		resultBuilder.WriteString(originalLines[lineIndex])
+	}
+
+	return resultBuilder.String()
+}
+
+func TestComputeTextEdits(t *testing.T) {
+	originalText := "a := 1\nif a {\n}\nb := 2\n\n\nc := 3\nd := 4"
+	formattedText := "a := 1\n\nif a {\n}\n\nb := 2\nc := 3\nd := 4\n"
+	textEdits := computeTextEdits(originalText, formattedText, 0, -1)
+
+	if len(textEdits) != 4 {
+		t.Errorf("expected 4 edits, got %d: %+v", len(textEdits), textEdits)
+	}
+
+	if resultText := applyTextEdits(originalText, textEdits); resultText != formattedText {
+		t.Errorf("applying edits got:\n%q\nwant:\n%q", resultText, formattedText)
+	}
+
+	lastTextEdit := textEdits[len(textEdits)-1]
+
+	if lastTextEdit.Range.End != (languageServerPosition{Line: 7, Character: 6}) {
+		t.Errorf("expected final edit to end at the last character, got %+v", lastTextEdit.Range.End)
+	}
+
+	rangeTextEdits := computeTextEdits(originalText, formattedText, 0, 3)
+
+	if len(rangeTextEdits) != 2 {
+		t.Errorf("expected 2 edits within lines 0-3, got %d: %+v", len(rangeTextEdits), rangeTextEdits)
+	}
+}
+
+func TestOnTypeFormatting(t *testing.T) {
+	documentURI := "file://" + filepath.ToSlash(filepath.Join(t.TempDir(), "main.go"))
+	testCases := []struct {
+		name             string
+		documentText     string
+		position         languageServerPosition
+		triggerCharacter string
+		expectedText     string
+	}{
+		{
+			"enter keeps the new line",
+			"package main\n\nfunc main() {\n\tx := 1\n\t\n}\n",
+			languageServerPosition{Line: 4, Character: 1},
+			"\n",
+			"package main\n\nfunc main() {\n\tx := 1\n\t\n}\n",
+		},
+		{
+			"enter formats the finished line",
+			"package main\n\nfunc main() {\n\tx := 1\n\tprintln(x)\n\t\n}\n",
+			languageServerPosition{Line: 5, Character: 1},
+			"\n",
+			"package main\n\nfunc main() {\n\tx := 1\n\n\tprintln(x)\n\t\n}\n",
+		},
+		{
+			"enter on a second blank line keeps it",
+			"package main\n\nfunc main() {\n\tx := 1\n\n\t\n}\n",
+			languageServerPosition{Line: 5, Character: 1},
+			"\n",
+			"package main\n\nfunc main() {\n\tx := 1\n\n\t\n}\n",
+		},
+		{
+			"enter on a second blank line formats the finished line",
+			"package main\n\nfunc main() {\n\tx := 1\n\tprintln(x)\n\n\t\n}\n",
+			languageServerPosition{Line: 6, Character: 1},
+			"\n",
+			"package main\n\nfunc main() {\n\tx := 1\n\n\tprintln(x)\n\n\t\n}\n",
+		},
+		{
+			"closing brace formats only its line",
+			"package main\nimport \"fmt\"\nfunc main() {\n\tif true {\n\t\tfmt.Println()\n\n\t}\n}\n",
+			languageServerPosition{Line: 6, Character: 2},
+			"}",
+			"package main\nimport \"fmt\"\nfunc main() {\n\tif true {\n\t\tfmt.Println()\n\t}\n}\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := &languageServer{documents: map[string]languageServerDocument{documentURI: {text: testCase.documentText, languageIdentifier: "go"}}}
+			parameters, _ := json.Marshal(map[string]any{
+				"textDocument": map[string]any{"uri": documentURI},
+				"position":     testCase.position,
+				"ch":           testCase.triggerCharacter,
+			})
+			result, responseError := server.format("textDocument/onTypeFormatting", parameters)
+
+			if responseError != nil {
+				t.Fatalf("format error: %v", responseError.Message)
+			}
+
+			textEdits := result.([]languageServerTextEdit)
+
+			for _, textEdit := range textEdits {
+				if textEdit.Range.Start.Line <= testCase.position.Line && testCase.position.Line < textEdit.Range.End.Line {
+					t.Errorf("edit %+v touches the cursor line", textEdit)
+				}
+			}
+
+			if resultText := applyTextEdits(testCase.documentText, textEdits); resultText != testCase.expectedText {
+				t.Errorf("got:\n%q\nwant:\n%q", resultText, testCase.expectedText)
+			}
+		})
+	}
+}
+
+func TestRangeFormattingExcludesRangeEndLine(t *testing.T) {
+	documentURI := "file://" + filepath.ToSlash(filepath.Join(t.TempDir(), "main.go"))
+	documentText := "package main\n\nfunc main() {\n\tx := 1\n\tprintln(x)\n\ty := 2\n\tprintln(y)\n}\n"
+	server := &languageServer{documents: map[string]languageServerDocument{documentURI: {text: documentText, languageIdentifier: "go"}}}
+	parameters, _ := json.Marshal(map[string]any{
+		"textDocument": map[string]any{"uri": documentURI},
+		"range":        languageServerRange{Start: languageServerPosition{Line: 3}, End: languageServerPosition{Line: 5}},
+	})
+	result, responseError := server.format("textDocument/rangeFormatting", parameters)
+
+	if responseError != nil {
+		t.Fatalf("format error: %v", responseError.Message)
+	}
+
+	expectedText := "package main\n\nfunc main() {\n\tx := 1\n\n\tprintln(x)\n\ty := 2\n\tprintln(y)\n}\n"
+
+	if resultText := applyTextEdits(documentText, result.([]languageServerTextEdit)); resultText != expectedText {
+		t.Errorf("got:\n%q\nwant:\n%q", resultText, expectedText)
+	}
+}
+
+func TestUTF16Length(t *testing.T) {
+	if unitCount := utf16Length("aé\U0001F600"); unitCount != 4 {
+		t.Errorf("expected 4 UTF-16 code units, got %d", unitCount)
+	}
+}
@@ -2,105 +2,417 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"github.com/Fuwn/iku/engine"
+	"github.com/Fuwn/iku/iku"
 	"io"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
-	"sync"
 )
 
 var version = "dev"
 var (
-	writeFlag   = flag.Bool("w", false, "write result to (source) file instead of stdout")
-	listFlag    = flag.Bool("l", false, "list files whose formatting differs from iku's")
-	diffFlag    = flag.Bool("d", false, "display diffs instead of rewriting files")
-	versionFlag = flag.Bool("version", false, "print version")
+	writeFlag            = flag.Bool("w", false, "write result to (source) file instead of stdout")
+	listFlag             = flag.Bool("l", false, "list files whose formatting differs from iku's")
+	diffFlag             = flag.Bool("d", false, "display diffs instead of rewriting files")
+	contextLinesFlag     = flag.Int("U", 3, "number of context lines to show in diffs")
+	checkFlag            = flag.Bool("check", false, "exit with status 1 if any file would be reformatted, 2 on errors")
+	includeGeneratedFlag = flag.Bool("include-generated", false, "format Go files marked with a \"Code generated ... DO NOT EDIT.\" header")
+	linesFlag            = stringList("lines", "only add or remove blank lines within `start:end` (1-based, inclusive; repeatable)")
+	diffBaseFlag         = flag.String("diff-base", "", "only format lines changed since this git `revision` (\"-\" reads a unified diff from stdin)")
+	stagedFlag           = flag.Bool("staged", false, "format files staged in the git index and write the results back to the index")
+	configFlag           = flag.String("config", "", "use the configuration file at `path` instead of discovering one for each file")
+	explainFlag          = flag.Bool("explain", false, "print each output line annotated with its classification and the blank-line rule applied before it")
+	jsonFlag             = flag.Bool("json", false, "with lint or -explain, print JSON objects, one per line")
+	excludeFlag          = stringList("exclude", "skip paths matching this gitignore-style pattern during directory walks (repeatable)")
+	versionFlag          = flag.Bool("version", false, "print version")
 )
 
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+
+	return nil
+}
+
+func stringList(name string, usage string) *stringListFlag {
+	values := &stringListFlag{}
+
+	flag.Var(values, name, usage)
+
+	return values
+}
+
+var configurations = newConfigurationResolver("")
+var subcommands = map[string]func(arguments []string) int{
+	"config": runConfigurationCommand,
+	"lint":   runLintCommand,
+	"lsp":    runLanguageServerCommand,
+}
+
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: iku [flags] [path ...]\n")
+		fmt.Fprintf(os.Stderr, "       iku config validate [file ...]\n")
+		fmt.Fprintf(os.Stderr, "       iku config print [-config path] file\n")
+		fmt.Fprintf(os.Stderr, "       iku lint [flags] [path ...]\n")
+		fmt.Fprintf(os.Stderr, "       iku lsp\n")
 		flag.PrintDefaults()
 	}
 
-	flag.Parse()
+	if len(os.Args) > 1 {
+		if subcommand, isSubcommand := subcommands[os.Args[1]]; isSubcommand {
+			os.Exit(subcommand(os.Args[2:]))
+		}
+	}
+
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(arguments []string) int {
+	_ = flag.CommandLine.Parse(arguments)
 
 	if *versionFlag {
 		fmt.Printf("%s (%s)\n", version, runtime.Version())
-		os.Exit(0)
+
+		return 0
+	}
+
+	if *configFlag != "" {
+		if _, err := iku.LoadConfigurationFile(*configFlag); err != nil {
+			printError(err)
+
+			return 2
+		}
+	}
+
+	configurations = newConfigurationResolver(*configFlag)
+
+	if *checkFlag && *writeFlag {
+		fmt.Fprintln(os.Stderr, "iku: cannot use -check with -w")
+
+		return 2
 	}
 
-	configuration := loadConfiguration()
-	commentMode, validationError := configuration.commentMode()
+	if lintMode && !checkLintFlags() {
+		return 2
+	}
+
+	if *explainFlag && (lintMode || *writeFlag || *listFlag || *diffFlag || *checkFlag) {
+		fmt.Fprintln(os.Stderr, "iku: cannot use -explain with lint, -w, -l, -d, or -check")
+
+		return 2
+	}
+
+	lineRanges, err := parseLineRanges(*linesFlag)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iku: %v\n", err)
 
-	if validationError != nil {
-		fmt.Fprintf(os.Stderr, "iku: %v\n", validationError)
-		os.Exit(2)
+		return 2
 	}
 
-	formatter := &Formatter{CommentMode: commentMode, Configuration: configuration}
+	if len(lineRanges) > 0 && flag.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "iku: cannot use -lines with more than one path")
+
+		return 2
+	}
+
+	options := iku.Options{LineRanges: lineRanges}
+	summary := &runSummary{}
+
+	if *stagedFlag {
+		if *writeFlag || *diffBaseFlag != "" || len(lineRanges) > 0 || flag.NArg() > 0 {
+			fmt.Fprintln(os.Stderr, "iku: cannot use -staged with -w, -diff-base, -lines, or paths")
+
+			return 2
+		}
+
+		if err := processStagedFiles(options, summary); err != nil {
+			summary.recordError(err)
+		}
+
+		return summary.finish()
+	}
+
+	if *diffBaseFlag != "" {
+		if len(lineRanges) > 0 {
+			fmt.Fprintln(os.Stderr, "iku: cannot use -lines with -diff-base")
+
+			return 2
+		}
+
+		if *diffBaseFlag == "-" && flag.NArg() > 0 {
+			fmt.Fprintln(os.Stderr, "iku: cannot use paths with -diff-base -")
+
+			return 2
+		}
+
+		if err := processChangedLines(options, *diffBaseFlag, flag.Args(), summary); err != nil {
+			summary.recordError(err)
+		}
+
+		return summary.finish()
+	}
 
 	if flag.NArg() == 0 {
 		if *writeFlag {
 			fmt.Fprintln(os.Stderr, "iku: cannot use -w with standard input")
-			os.Exit(2)
-		}
 
-		if err := processFile(formatter, "<stdin>", os.Stdin, os.Stdout, false); err != nil {
-			fmt.Fprintf(os.Stderr, "iku: %v\n", err)
-			os.Exit(1)
+			return 2
 		}
 
-		return
-	}
+		status, err := processStandardInput(options)
 
-	exitCode := 0
+		summary.record("<stdin>", status, err)
+
+		return summary.finish()
+	}
 
 	for _, argumentPath := range flag.Args() {
 		switch fileInfo, err := os.Stat(argumentPath); {
 		case err != nil:
-			fmt.Fprintf(os.Stderr, "iku: %v\n", err)
-
-			exitCode = 1
+			summary.recordError(err)
+		case fileInfo.IsDir() && len(lineRanges) > 0:
+			summary.recordError(fmt.Errorf("%s: cannot use -lines with a directory", argumentPath))
 		case fileInfo.IsDir():
-			if err := processDirectory(formatter, argumentPath, &exitCode); err != nil {
-				fmt.Fprintf(os.Stderr, "iku: %v\n", err)
-
-				exitCode = 1
+			if err := processDirectory(options, argumentPath, summary); err != nil {
+				summary.recordError(err)
 			}
 		default:
-			if err := processFilePath(formatter, argumentPath, &exitCode); err != nil {
-				fmt.Fprintf(os.Stderr, "iku: %v\n", err)
+			status, err := processFilePath(options, argumentPath, os.Stdout)
 
-				exitCode = 1
-			}
+			summary.record(argumentPath, status, err)
 		}
 	}
 
-	os.Exit(exitCode)
+	return summary.finish()
 }
 
-var supportedFileExtensions = map[string]bool{
-	".go":  true,
-	".js":  true,
-	".ts":  true,
-	".jsx": true,
-	".tsx": true,
+func parseLineRanges(rangeValues []string) ([]engine.LineRange, error) {
+	var lineRanges []engine.LineRange
+
+	for _, rangeValue := range rangeValues {
+		startValue, endValue, hasSeparator := strings.Cut(rangeValue, ":")
+
+		if !hasSeparator {
+			return nil, fmt.Errorf("invalid -lines value %q (use start:end)", rangeValue)
+		}
+
+		startLine, startError := strconv.Atoi(startValue)
+		endLine, endError := strconv.Atoi(endValue)
+
+		if startError != nil || endError != nil || startLine < 1 || endLine < startLine {
+			return nil, fmt.Errorf("invalid -lines value %q (use start:end with 1 <= start <= end)", rangeValue)
+		}
+
+		lineRanges = append(lineRanges, engine.LineRange{Start: startLine, End: endLine})
+	}
+
+	return lineRanges, nil
+}
+
+func printError(err error) {
+	if joinedError, isJoined := err.(interface{ Unwrap() []error }); isJoined {
+		for _, wrappedError := range joinedError.Unwrap() {
+			printError(wrappedError)
+		}
+
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "iku: %v\n", err)
+}
+
+type fileStatus int
+
+const (
+	fileUnchanged fileStatus = iota
+	fileReformatted
+	fileSkippedGenerated
+)
+
+type runSummary struct {
+	processedFileCount         int
+	reformattedFileCount       int
+	skippedFileCount           int
+	reportedConfigurationPaths map[string]bool
+	hasConfigurationError      bool
+	hasSourceError             bool
+	hasError                   bool
+}
+
+func (s *runSummary) record(filePath string, status fileStatus, err error) {
+	if err != nil {
+		s.recordError(err)
+
+		return
+	}
+
+	switch status {
+	case fileSkippedGenerated:
+		s.skippedFileCount++
+
+		if *listFlag || *checkFlag {
+			fmt.Fprintf(os.Stderr, "iku: %s: skipped generated file\n", filePath)
+		}
+	case fileReformatted:
+		s.processedFileCount++
+		s.reformattedFileCount++
+	default:
+		s.processedFileCount++
+	}
+}
+
+func (s *runSummary) recordError(err error) {
+	var parseError *iku.ParseError
+	var configurationError *iku.ConfigurationError
+
+	if errors.As(err, &configurationError) {
+		s.hasConfigurationError = true
+
+		if s.reportedConfigurationPaths[configurationError.Path] {
+			return
+		}
+
+		if s.reportedConfigurationPaths == nil {
+			s.reportedConfigurationPaths = make(map[string]bool)
+		}
+
+		s.reportedConfigurationPaths[configurationError.Path] = true
+	}
+
+	printError(err)
+
+	if errors.As(err, &parseError) {
+		s.processedFileCount++
+
+		s.hasSourceError = true
+	}
+
+	s.hasError = true
 }
 
-func processDirectory(formatter *Formatter, directoryPath string, exitCode *int) error {
+func (s *runSummary) finish() int {
+	if lintMode {
+		switch {
+		case s.hasSourceError || s.hasConfigurationError:
+			return 2
+		case s.hasError || s.reformattedFileCount > 0:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	if !*checkFlag {
+		if s.hasConfigurationError {
+			return 2
+		}
+
+		if s.hasError {
+			return 1
+		}
+
+		return 0
+	}
+
+	fileNoun := "files"
+
+	if s.processedFileCount == 1 {
+		fileNoun = "file"
+	}
+
+	fmt.Fprintf(os.Stderr, "%d of %d %s would be reformatted", s.reformattedFileCount, s.processedFileCount, fileNoun)
+
+	if s.skippedFileCount > 0 {
+		fmt.Fprintf(os.Stderr, " (%d generated skipped)", s.skippedFileCount)
+	}
+
+	fmt.Fprintln(os.Stderr)
+
+	switch {
+	case s.hasError:
+		return 2
+	case s.reformattedFileCount > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type fileResult struct {
+	filePath string
+	output   bytes.Buffer
+	status   fileStatus
+	err      error
+	done     chan struct{}
+}
+
+func processDirectory(options iku.Options, directoryPath string, summary *runSummary) error {
 	var sourceFilePaths []string
 
-	err := filepath.WalkDir(directoryPath, func(currentPath string, dirEntry fs.DirEntry, err error) error {
+	absoluteDirectoryPath, err := filepath.Abs(directoryPath)
+
+	if err != nil {
+		return err
+	}
+
+	rootMatcher, err := newIgnoreMatcher(absoluteDirectoryPath, *excludeFlag)
+
+	if err != nil {
+		return err
+	}
+
+	directoryMatchers := make(map[string]*ignoreMatcher)
+	err = filepath.WalkDir(directoryPath, func(currentPath string, dirEntry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if !dirEntry.IsDir() && supportedFileExtensions[filepath.Ext(currentPath)] {
+		relativePath, err := filepath.Rel(directoryPath, currentPath)
+
+		if err != nil {
+			return err
+		}
+
+		absolutePath := filepath.Join(absoluteDirectoryPath, relativePath)
+		parentMatcher, hasParentMatcher := directoryMatchers[filepath.Dir(currentPath)]
+
+		if !hasParentMatcher {
+			parentMatcher = rootMatcher
+		}
+
+		if dirEntry.IsDir() {
+			if relativePath != "." && (dirEntry.Name() == ".git" || parentMatcher.isIgnored(absolutePath, true)) {
+				return filepath.SkipDir
+			}
+
+			directoryMatcher, err := parentMatcher.withDirectory(absolutePath)
+
+			if err != nil {
+				return err
+			}
+
+			directoryMatchers[filepath.Clean(currentPath)] = directoryMatcher
+
+			return nil
+		}
+
+		if fileConfiguration, _, _ := configurations.resolve(currentPath); fileConfiguration.IsSupportedFile(currentPath) && !parentMatcher.isIgnored(absolutePath, false) {
 			sourceFilePaths = append(sourceFilePaths, currentPath)
 		}
 
@@ -111,120 +423,160 @@ func processDirectory(formatter *Formatter, directoryPath string, exitCode *int)
 		return err
 	}
 
-	var waitGroup sync.WaitGroup
-	var mutex sync.Mutex
-
+	fileResults := make([]*fileResult, len(sourceFilePaths))
 	semaphore := make(chan struct{}, runtime.NumCPU())
 
-	for _, filePath := range sourceFilePaths {
-		waitGroup.Add(1)
+	for fileIndex, filePath := range sourceFilePaths {
+		currentResult := &fileResult{filePath: filePath, done: make(chan struct{})}
+		fileResults[fileIndex] = currentResult
 
 		go func(currentFilePath string) {
-			defer waitGroup.Done()
+			defer close(currentResult.done)
 
 			semaphore <- struct{}{}
 
 			defer func() { <-semaphore }()
 
-			if err := processFilePath(formatter, currentFilePath, exitCode); err != nil {
-				mutex.Lock()
-				fmt.Fprintf(os.Stderr, "iku: %v\n", err)
-
-				*exitCode = 1
-
-				mutex.Unlock()
-			}
+			currentResult.status, currentResult.err = processFilePath(options, currentFilePath, &currentResult.output)
 		}(filePath)
 	}
 
-	waitGroup.Wait()
+	for _, currentResult := range fileResults {
+		<-currentResult.done
+
+		_, _ = os.Stdout.Write(currentResult.output.Bytes())
+
+		summary.record(currentResult.filePath, currentResult.status, currentResult.err)
+	}
 
 	return nil
 }
 
-func processFilePath(formatter *Formatter, filePath string, _ *int) error {
-	sourceFile, err := os.Open(filePath)
+func processChangedLines(options iku.Options, diffBase string, pathArguments []string, summary *runSummary) error {
+	var changedLineRanges map[string][]engine.LineRange
 
-	if err != nil {
-		return err
+	if diffBase == "-" {
+		diffContent, err := io.ReadAll(os.Stdin)
+
+		if err != nil {
+			return err
+		}
+
+		if changedLineRanges, err = parseDiffLineRanges(diffContent); err != nil {
+			return err
+		}
+	} else {
+		var err error
+
+		if changedLineRanges, err = gitChangedLineRanges("", diffBase, pathArguments); err != nil {
+			return err
+		}
 	}
 
-	defer func() { _ = sourceFile.Close() }()
+	for _, changedPath := range slices.Sorted(maps.Keys(changedLineRanges)) {
+		if fileConfiguration, _, err := configurations.resolve(changedPath); err == nil && !fileConfiguration.IsSupportedFile(changedPath) {
+			continue
+		}
 
-	var outputDestination io.Writer = os.Stdout
+		fileOptions := options
+		fileOptions.LineRanges = changedLineRanges[changedPath]
+		status, err := processFilePath(fileOptions, changedPath, os.Stdout)
 
-	if *writeFlag {
-		outputDestination = nil
+		summary.record(changedPath, status, err)
 	}
 
-	return processFile(formatter, filePath, sourceFile, outputDestination, true)
+	return nil
 }
 
-func processFile(formatter *Formatter, filename string, inputReader io.Reader, outputWriter io.Writer, isFile bool) error {
-	sourceContent, err := io.ReadAll(inputReader)
+func processStandardInput(options iku.Options) (fileStatus, error) {
+	options, err := configurations.resolveOptions(options, "<stdin>")
 
 	if err != nil {
-		return fmt.Errorf("%s: %v", filename, err)
+		return fileUnchanged, err
 	}
 
-	formattedResult, err := formatter.Format(sourceContent, filename)
+	return processFile(options, "<stdin>", os.Stdin, os.Stdout, false)
+}
+
+func processFilePath(options iku.Options, filePath string, outputWriter io.Writer) (fileStatus, error) {
+	options, err := configurations.resolveOptions(options, filePath)
 
 	if err != nil {
-		return fmt.Errorf("%s: %v", filename, err)
+		return fileUnchanged, err
 	}
 
-	if *listFlag {
-		if !bytes.Equal(sourceContent, formattedResult) {
-			fmt.Println(filename)
-		}
+	sourceFile, err := os.Open(filePath)
 
-		return nil
+	if err != nil {
+		return fileUnchanged, err
 	}
 
-	if *diffFlag {
-		if !bytes.Equal(sourceContent, formattedResult) {
-			diffOutput := unifiedDiff(filename, sourceContent, formattedResult)
-			_, _ = os.Stdout.Write(diffOutput)
-		}
+	defer func() { _ = sourceFile.Close() }()
 
-		return nil
+	return processFile(options, filePath, sourceFile, outputWriter, true)
+}
+
+func processFile(options iku.Options, filename string, inputReader io.Reader, outputWriter io.Writer, isFile bool) (fileStatus, error) {
+	sourceContent, err := io.ReadAll(inputReader)
+
+	if err != nil {
+		return fileUnchanged, fmt.Errorf("%s: %v", filename, err)
 	}
 
-	if *writeFlag && isFile {
-		if !bytes.Equal(sourceContent, formattedResult) {
-			return os.WriteFile(filename, formattedResult, 0644)
+	if !*includeGeneratedFlag && iku.IsGenerated(sourceContent, filename) {
+		if !*listFlag && !*checkFlag && !*diffFlag && !lintMode && !*explainFlag && !(*writeFlag && isFile) {
+			_, err = outputWriter.Write(sourceContent)
 		}
 
-		return nil
+		return fileSkippedGenerated, err
 	}
 
-	if outputWriter != nil {
-		_, err = outputWriter.Write(formattedResult)
+	if lintMode {
+		return lintFile(options, filename, sourceContent, outputWriter)
+	}
 
-		return err
+	if *explainFlag {
+		return explainFile(options, filename, sourceContent, outputWriter)
 	}
 
-	return nil
-}
+	formattedResult, err := iku.Format(sourceContent, filename, options)
 
-func unifiedDiff(filename string, originalSource, formattedSource []byte) []byte {
-	var outputBuffer bytes.Buffer
+	if err != nil {
+		return fileUnchanged, err
+	}
 
-	fmt.Fprintf(&outputBuffer, "--- %s\n", filename)
-	fmt.Fprintf(&outputBuffer, "+++ %s\n", filename)
+	status := fileUnchanged
 
-	originalSourceLines := strings.Split(string(originalSource), "\n")
-	formattedSourceLines := strings.Split(string(formattedSource), "\n")
+	if !bytes.Equal(sourceContent, formattedResult) {
+		status = fileReformatted
+	}
 
-	fmt.Fprintf(&outputBuffer, "@@ -1,%d +1,%d @@\n", len(originalSourceLines), len(formattedSourceLines))
+	if *listFlag || *checkFlag && !*diffFlag {
+		if status == fileReformatted {
+			fmt.Fprintln(outputWriter, filename)
+		}
 
-	for _, currentLine := range originalSourceLines {
-		fmt.Fprintf(&outputBuffer, "-%s\n", currentLine)
+		return status, nil
 	}
 
-	for _, currentLine := range formattedSourceLines {
-		fmt.Fprintf(&outputBuffer, "+%s\n", currentLine)
+	if *diffFlag {
+		if status == fileReformatted {
+			diffOutput := unifiedDiff(filename, sourceContent, formattedResult, *contextLinesFlag)
+			_, _ = outputWriter.Write(diffOutput)
+		}
+
+		return status, nil
 	}
 
-	return outputBuffer.Bytes()
+	if *writeFlag && isFile {
+		if status == fileReformatted {
+			return status, os.WriteFile(filename, formattedResult, 0644)
+		}
+
+		return status, nil
+	}
+
+	_, err = outputWriter.Write(formattedResult)
+
+	return status, err
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRunSummaryCheckExitCode(t *testing.T) {
+	defer func(wasCheck bool) { *checkFlag = wasCheck }(*checkFlag)
+
+	*checkFlag = true
+	testCases := []struct {
+		name             string
+		status           fileStatus
+		err              error
+		expectedExitCode int
+	}{
+		{"formatted", fileUnchanged, nil, 0},
+		{"needs formatting", fileReformatted, nil, 1},
+		{"read error", fileUnchanged, errors.New("stat missing.go: no such file or directory"), 2},
+	}
+
+	for _, testCase := range testCases {
+		summary := &runSummary{}
+
+		summary.record("main.go", testCase.status, testCase.err)
+
+		if exitCode := summary.finish(); exitCode != testCase.expectedExitCode {
+			t.Errorf("%s: got exit code %d, want %d", testCase.name, exitCode, testCase.expectedExitCode)
+		}
+	}
+}